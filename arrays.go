@@ -0,0 +1,179 @@
+package encoder
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// WriteUint64ArrayKey writes the key and the values as an array.
+func (enc *Encoder) WriteUint64ArrayKey(key []byte, values []uint64, append_delim bool) {
+	enc.ObjectKey(key)
+	enc.writeUint64Array(values)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteInt64ArrayKey writes the key and the values as an array.
+func (enc *Encoder) WriteInt64ArrayKey(key []byte, values []int64, append_delim bool) {
+	enc.ObjectKey(key)
+	enc.writeInt64Array(values)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteFloat64ArrayKey writes the key and the values, rounded if configured,
+// as an array.
+func (enc *Encoder) WriteFloat64ArrayKey(key []byte, values []float64, append_delim bool) {
+	enc.ObjectKey(key)
+	enc.writeFloat64Array(values)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteStringArrayKey writes the key and the escaped values as an array.
+func (enc *Encoder) WriteStringArrayKey(key []byte, values []string, append_delim bool) {
+	enc.ObjectKey(key)
+	enc.writeStringArray(values)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// Uint64ArrayField writes the key and the values as an array. Delimiters are
+// managed by the Encoder.
+func (enc *Encoder) Uint64ArrayField(key []byte, values []uint64) {
+	enc.WriteUint64ArrayKey(key, values, false)
+}
+
+// Int64ArrayField writes the key and the values as an array. Delimiters are
+// managed by the Encoder.
+func (enc *Encoder) Int64ArrayField(key []byte, values []int64) {
+	enc.WriteInt64ArrayKey(key, values, false)
+}
+
+// Float64ArrayField writes the key and the values, rounded if configured, as
+// an array. Delimiters are managed by the Encoder.
+func (enc *Encoder) Float64ArrayField(key []byte, values []float64) {
+	enc.WriteFloat64ArrayKey(key, values, false)
+}
+
+// StringArrayField writes the key and the escaped values as an array.
+// Delimiters are managed by the Encoder.
+func (enc *Encoder) StringArrayField(key []byte, values []string) {
+	enc.WriteStringArrayKey(key, values, false)
+}
+
+// Uint64Array writes the values as an array element.
+func (enc *Encoder) Uint64Array(values []uint64) {
+	enc.writeUint64Array(values)
+}
+
+// Int64Array writes the values as an array element.
+func (enc *Encoder) Int64Array(values []int64) {
+	enc.writeInt64Array(values)
+}
+
+// Float64Array writes the values, rounded if configured, as an array element.
+func (enc *Encoder) Float64Array(values []float64) {
+	enc.writeFloat64Array(values)
+}
+
+// StringArray writes the escaped values as an array element.
+func (enc *Encoder) StringArray(values []string) {
+	enc.writeStringArray(values)
+}
+
+// The array writers write all elements in a single loop, formatting each one
+// into the same scratch buffer and writing it straight to the encoder buffer.
+
+func (enc *Encoder) writeUint64Array(values []uint64) {
+	b := bufPool.Get().(*bytes.Buffer)
+	b.Reset()
+	defer bufPool.Put(b)
+
+	// room for the longest formatted value.
+	b.Grow(64)
+	scratch := b.AvailableBuffer()
+
+	enc.ArrayStart()
+	for i, v := range values {
+		if !enc.ok() {
+			break
+		}
+		if i > 0 {
+			enc.b.WriteByte(delim)
+		}
+		enc.b.Write(strconv.AppendUint(scratch, v, 10))
+		enc.flush()
+	}
+	enc.ArrayEnd()
+}
+
+func (enc *Encoder) writeInt64Array(values []int64) {
+	b := bufPool.Get().(*bytes.Buffer)
+	b.Reset()
+	defer bufPool.Put(b)
+
+	// room for the longest formatted value.
+	b.Grow(64)
+	scratch := b.AvailableBuffer()
+
+	enc.ArrayStart()
+	for i, v := range values {
+		if !enc.ok() {
+			break
+		}
+		if i > 0 {
+			enc.b.WriteByte(delim)
+		}
+		enc.b.Write(strconv.AppendInt(scratch, v, 10))
+		enc.flush()
+	}
+	enc.ArrayEnd()
+}
+
+func (enc *Encoder) writeFloat64Array(values []float64) {
+	b := bufPool.Get().(*bytes.Buffer)
+	b.Reset()
+	defer bufPool.Put(b)
+
+	// room for the longest formatted value.
+	b.Grow(64)
+	scratch := b.AvailableBuffer()
+
+	enc.ArrayStart()
+	for i, v := range values {
+		if !enc.ok() {
+			break
+		}
+		if i > 0 {
+			enc.b.WriteByte(delim)
+		}
+		enc.b.Write(enc.appendFloat64(scratch, v))
+		enc.flush()
+	}
+	enc.ArrayEnd()
+}
+
+func (enc *Encoder) writeStringArray(values []string) {
+	enc.ArrayStart()
+	for i, v := range values {
+		if !enc.ok() {
+			break
+		}
+		if i > 0 {
+			enc.b.WriteByte(delim)
+		}
+		enc.b.WriteByte(quoteMark)
+		writeEscaped(enc, v)
+		enc.b.WriteByte(quoteMark)
+	}
+	enc.ArrayEnd()
+}
@@ -0,0 +1,158 @@
+package encoder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncoderWriteUint64ArrayKey(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	key := []byte("/foo/bar")
+	enc.WriteUint64ArrayKey(key, []uint64{1, 2, 18446744073709551615}, true)
+	assert.Equal(t, `"/foo/bar":[1,2,18446744073709551615],`, enc.b.String())
+
+	enc.b.Reset()
+	enc.WriteUint64ArrayKey(key, nil, false)
+	assert.Equal(t, `"/foo/bar":[]`, enc.b.String())
+}
+
+func TestEncoderWriteInt64ArrayKey(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	key := []byte("/foo/bar")
+	enc.WriteInt64ArrayKey(key, []int64{-1, 0, 1}, false)
+	assert.Equal(t, `"/foo/bar":[-1,0,1]`, enc.b.String())
+}
+
+func TestEncoderWriteFloat64ArrayKey(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	key := []byte("/foo/bar")
+	enc.WriteFloat64ArrayKey(key, []float64{0.00010732467532467535, -1.5, 2}, true)
+	assert.Equal(t, `"/foo/bar":[0.000107,-1.5,2],`, enc.b.String())
+
+	enc.b.Reset()
+	enc.c.Round = false
+	enc.WriteFloat64ArrayKey(key, []float64{0.00010732467532467535}, false)
+	assert.Equal(t, `"/foo/bar":[0.00010732467532467535]`, enc.b.String())
+}
+
+func TestEncoderWriteStringArrayKey(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	key := []byte("/foo/bar")
+	enc.WriteStringArrayKey(key, []string{"a", `"b"`, ""}, false)
+	assert.Equal(t, `"/foo/bar":["a","\"b\"",""]`, enc.b.String())
+}
+
+func TestEncoderArrayFields(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+
+	enc.ObjectStart()
+	enc.Uint64ArrayField([]byte("a"), []uint64{1})
+	enc.Int64ArrayField([]byte("b"), []int64{-1})
+	enc.Float64ArrayField([]byte("c"), []float64{0.5})
+	enc.StringArrayField([]byte("d"), []string{"x"})
+	enc.ObjectEnd()
+	assert.Equal(t, `{"a":[1],"b":[-1],"c":[0.5],"d":["x"]}`, enc.b.String())
+}
+
+func TestEncoderArrayCanceled(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	enc.cancel()
+
+	// a canceled encoder drops the values.
+	enc.WriteUint64ArrayKey([]byte("a"), make([]uint64, 10), false)
+	assert.Equal(t, 0, enc.Len())
+}
+
+var float64Values = func() []float64 {
+	values := make([]float64, 100)
+	for i := range values {
+		values[i] = float64(i) / 7
+	}
+	return values
+}()
+
+var uint64Values = func() []uint64 {
+	values := make([]uint64, 100)
+	for i := range values {
+		values[i] = uint64(i) * 473829
+	}
+	return values
+}()
+
+func BenchmarkEncoderWriteUint64ArrayKey(b *testing.B) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	key := []byte("/foo/bar")
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		enc.b.Reset()
+		enc.WriteUint64ArrayKey(key, uint64Values, false)
+	}
+}
+
+func BenchmarkEncoderUint64Elements(b *testing.B) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	key := []byte("/foo/bar")
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		enc.b.Reset()
+		enc.ObjectKey(key)
+		enc.ArrayStart()
+		for _, v := range uint64Values {
+			enc.Uint64(v)
+		}
+		enc.ArrayEnd()
+	}
+}
+
+func BenchmarkEncoderWriteFloat64ArrayKey(b *testing.B) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	key := []byte("/foo/bar")
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		enc.b.Reset()
+		enc.WriteFloat64ArrayKey(key, float64Values, false)
+	}
+}
+
+func BenchmarkEncoderFloat64Elements(b *testing.B) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	key := []byte("/foo/bar")
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		enc.b.Reset()
+		enc.ObjectKey(key)
+		enc.ArrayStart()
+		for _, v := range float64Values {
+			enc.Float64(v)
+		}
+		enc.ArrayEnd()
+	}
+}
+
+func TestEncoderArrayElements(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+
+	enc.ArrayStart()
+	enc.Uint64Array([]uint64{1})
+	enc.Int64Array([]int64{-1, 2})
+	enc.Float64Array(nil)
+	enc.StringArray([]string{"a"})
+	enc.ArrayEnd()
+	assert.Equal(t, `[[1],[-1,2],[],["a"]]`, enc.b.String())
+}
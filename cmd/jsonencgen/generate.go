@@ -0,0 +1,364 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// annotation marks a struct for which an EncodeJSON method is generated.
+const annotation = "//jsonenc:generate"
+
+// importPath of the encoder package.
+const importPath = "github.com/simook/jsonencoder"
+
+// field describes how a single struct field is written.
+type field struct {
+	name string // json key.
+	key  string // variable holding the Key of name.
+	expr string // Go expression of the value, i.e. v.X
+	call string // Encoder method writing the value, following the key.
+	arg  string // argument of call, i.e. float64(v.X)
+	set  string // omitempty: condition under which the field is written, if any.
+}
+
+// object is an annotated struct.
+type object struct {
+	name   string
+	fields []field
+}
+
+// Generate parses the Go source of file and returns the source of the
+// EncodeJSON methods of its annotated structs, or nil if there are none.
+func Generate(file string, src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	// collect the annotated structs first, so fields may refer to structs
+	// declared further down the file.
+	annotated := map[string]bool{}
+	var specs []*ast.TypeSpec
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if _, ok := ts.Type.(*ast.StructType); !ok {
+				continue
+			}
+			if ts.TypeParams != nil {
+				continue
+			}
+			if !isAnnotated(ts.Doc) && !(len(gd.Specs) == 1 && isAnnotated(gd.Doc)) {
+				continue
+			}
+			annotated[ts.Name.Name] = true
+			specs = append(specs, ts)
+		}
+	}
+
+	if len(specs) == 0 {
+		return nil, nil
+	}
+
+	imports := importNames(f)
+
+	var objects []object
+	for _, ts := range specs {
+		obj := object{name: ts.Name.Name}
+		names := map[string]bool{}
+		for _, fl := range ts.Type.(*ast.StructType).Fields.List {
+			if len(fl.Names) == 0 {
+				return nil, fmt.Errorf("%s: %s: embedded field %s is not supported",
+					fset.Position(fl.Pos()), obj.name, types(fl.Type))
+			}
+
+			tag := ""
+			if fl.Tag != nil {
+				tag, _ = strconv.Unquote(fl.Tag.Value)
+			}
+			name, opts := parseTag(reflect.StructTag(tag).Get("json"))
+			if name == "-" && opts == "" {
+				continue
+			}
+
+			for _, id := range fl.Names {
+				if !id.IsExported() {
+					continue
+				}
+
+				fd := field{name: name, expr: "v." + id.Name}
+				if fd.name == "" {
+					fd.name = id.Name
+				}
+				if err := fd.resolve(fl.Type, opts, imports, annotated); err != nil {
+					return nil, fmt.Errorf("%s: %s.%s: %v",
+						fset.Position(id.Pos()), obj.name, id.Name, err)
+				}
+				if !hasOpt(opts, "omitempty") {
+					fd.set = ""
+				}
+				if names[fd.name] {
+					return nil, fmt.Errorf("%s: %s.%s: duplicate key %q",
+						fset.Position(id.Pos()), obj.name, id.Name, fd.name)
+				}
+				names[fd.name] = true
+				obj.fields = append(obj.fields, fd)
+			}
+		}
+		objects = append(objects, obj)
+	}
+
+	return render(f.Name.Name, objects)
+}
+
+// isAnnotated reports whether the comment group holds the annotation.
+func isAnnotated(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+	for _, c := range doc.List {
+		if strings.TrimSpace(c.Text) == annotation {
+			return true
+		}
+	}
+	return false
+}
+
+// importNames maps the local names of the imports of f to their paths.
+func importNames(f *ast.File) map[string]string {
+	names := map[string]string{}
+	for _, imp := range f.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		name := path[strings.LastIndex(path, "/")+1:]
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		names[name] = path
+	}
+	return names
+}
+
+// parseTag splits a json struct tag into its name and options.
+func parseTag(tag string) (string, string) {
+	name, opts, _ := strings.Cut(tag, ",")
+	return name, opts
+}
+
+// hasOpt reports whether the comma separated options hold opt.
+func hasOpt(opts, opt string) bool {
+	for opts != "" {
+		var o string
+		o, opts, _ = strings.Cut(opts, ",")
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// types returns the source representation of a type expression.
+func types(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return types(t.X) + "." + t.Sel.Name
+	case *ast.StarExpr:
+		return "*" + types(t.X)
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return "[]" + types(t.Elt)
+		}
+		return "[...]" + types(t.Elt)
+	case *ast.MapType:
+		return "map[" + types(t.Key) + "]" + types(t.Value)
+	}
+	return fmt.Sprintf("%T", expr)
+}
+
+// resolve picks the Encoder method writing a field of type expr.
+func (fd *field) resolve(expr ast.Expr, opts string, imports map[string]string, annotated map[string]bool) error {
+	quoted := hasOpt(opts, "string")
+	typ := types(expr)
+
+	// qualified types are matched by import path, not by local name.
+	if sel, ok := expr.(*ast.SelectorExpr); ok {
+		if pkg, ok := sel.X.(*ast.Ident); ok {
+			typ = imports[pkg.Name] + "." + sel.Sel.Name
+		}
+	}
+
+	if hasOpt(opts, "timestamp") && typ != "uint32" {
+		return fmt.Errorf("timestamp option requires a uint32, not %s", typ)
+	}
+
+	fd.arg = fd.expr
+	switch typ {
+	case "uint8", "uint16", "uint32":
+		fd.call, fd.set = "Uint32", fd.expr+" != 0"
+		if typ != "uint32" {
+			fd.arg = "uint32(" + fd.expr + ")"
+		}
+		if hasOpt(opts, "timestamp") {
+			fd.call = "Timestamp"
+			return nil
+		}
+		if quoted {
+			fd.call = "Encoded" + fd.call
+		}
+	case "uint", "uint64", "uintptr":
+		fd.call, fd.set = "Uint64", fd.expr+" != 0"
+		if typ != "uint64" {
+			fd.arg = "uint64(" + fd.expr + ")"
+		}
+		if quoted {
+			fd.call = "Encoded" + fd.call
+		}
+	case "int8", "int16", "int32":
+		fd.call, fd.set = "Int32", fd.expr+" != 0"
+		if typ != "int32" {
+			fd.arg = "int32(" + fd.expr + ")"
+		}
+		if quoted {
+			fd.call = "Encoded" + fd.call
+		}
+	case "int", "int64":
+		fd.call, fd.set = "Int64", fd.expr+" != 0"
+		if typ != "int64" {
+			fd.arg = "int64(" + fd.expr + ")"
+		}
+		if quoted {
+			fd.call = "Encoded" + fd.call
+		}
+	case "float32", "float64":
+		fd.call, fd.set = "Float64", fd.expr+" != 0"
+		if typ == "float32" {
+			fd.arg = "float64(" + fd.expr + ")"
+		}
+		if quoted {
+			fd.call = "Encoded" + fd.call
+		}
+	case "string":
+		fd.call, fd.set = "Str", fd.expr+` != ""`
+		if quoted {
+			fd.call = "Encoded" + fd.call
+		}
+	case "bool":
+		fd.call, fd.set = "Bool", fd.expr
+		if quoted {
+			fd.call = "Encoded" + fd.call
+		}
+	case "time.Time":
+		fd.call = "Time"
+	case "[]uint64", "[]int64", "[]float64", "[]string":
+		elem := typ[2:]
+		fd.call = strings.ToUpper(elem[:1]) + elem[1:] + "Array"
+		fd.set = "len(" + fd.expr + ") > 0"
+	case "*int64", "*uint64", "*float64", "*string", "*bool":
+		elem := typ[1:]
+		fd.call = strings.ToUpper(elem[:1]) + elem[1:] + "Ptr"
+		fd.set = fd.expr + " != nil"
+	case "database/sql.NullInt64", "database/sql.NullFloat64", "database/sql.NullString", "database/sql.NullTime":
+		fd.call = strings.TrimPrefix(typ, "database/sql.")
+	default:
+		fd.resolveValue(expr, annotated)
+	}
+
+	return nil
+}
+
+// resolveValue writes annotated structs of the same file with their
+// generated EncodeJSON method and falls back to reflection otherwise. Like
+// encoding/json, omitempty never omits a struct.
+func (fd *field) resolveValue(expr ast.Expr, annotated map[string]bool) {
+	fd.call = "Encode"
+
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if annotated[t.Name] {
+			fd.call, fd.arg = "Value", "&"+fd.expr
+		}
+	case *ast.StarExpr:
+		fd.set = fd.expr + " != nil"
+		if id, ok := t.X.(*ast.Ident); ok && annotated[id.Name] {
+			// Value writes a nil pointer as null.
+			fd.call = "Value"
+		}
+	case *ast.ArrayType, *ast.MapType:
+		fd.set = "len(" + fd.expr + ") > 0"
+	case *ast.InterfaceType:
+		fd.set = fd.expr + " != nil"
+	}
+}
+
+// render returns the formatted source of the generated file.
+func render(pkg string, objects []object) ([]byte, error) {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "// Code generated by jsonencgen; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	fmt.Fprintf(&b, "import encoder %q\n", importPath)
+
+	// the names of the key variables declared so far, as keyVar may map
+	// different keys to the same name, i.e. "a-b" and "a_2d_b".
+	vars := map[string]bool{}
+	for _, obj := range objects {
+		if len(obj.fields) > 0 {
+			b.WriteString("\nvar (\n")
+			for i := range obj.fields {
+				fd := &obj.fields[i]
+				fd.key = keyVar(obj.name, fd.name)
+				for n := 2; vars[fd.key]; n++ {
+					fd.key = fmt.Sprintf("%s_%d", keyVar(obj.name, fd.name), n)
+				}
+				vars[fd.key] = true
+				fmt.Fprintf(&b, "%s = encoder.NewKey(%q)\n", fd.key, fd.name)
+			}
+			b.WriteString(")\n")
+		}
+
+		fmt.Fprintf(&b, "\n// EncodeJSON writes %s as a JSON object.\n", obj.name)
+		fmt.Fprintf(&b, "func (v *%s) EncodeJSON(enc *encoder.Encoder) {\n", obj.name)
+		b.WriteString("enc.ObjectStart()\n")
+		// the Encoder inserts the delimiters.
+		for _, fd := range obj.fields {
+			call := fmt.Sprintf("enc.Key(%s)\nenc.%s(%s)\n", fd.key, fd.call, fd.arg)
+			if fd.set != "" {
+				call = fmt.Sprintf("if %s {\n%s}\n", fd.set, call)
+			}
+			b.WriteString(call)
+		}
+		b.WriteString("enc.ObjectEnd()\n}\n")
+	}
+
+	return format.Source(b.Bytes())
+}
+
+// keyVar returns the name of the variable holding the key of a field. The
+// name is not unique, see render.
+func keyVar(obj, key string) string {
+	var b strings.Builder
+	b.WriteString("jsonenc")
+	b.WriteString(obj)
+	b.WriteByte('_')
+	for _, r := range key {
+		if r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' {
+			b.WriteRune(r)
+			continue
+		}
+		fmt.Fprintf(&b, "_%x_", r)
+	}
+	return b.String()
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateUpToDate(t *testing.T) {
+	src, err := os.ReadFile("internal/example/example.go")
+	assert.NoError(t, err)
+
+	code, err := Generate("example.go", src)
+	assert.NoError(t, err)
+
+	want, err := os.ReadFile("internal/example/example_jsonenc.go")
+	assert.NoError(t, err)
+	assert.Equal(t, string(want), string(code), "run go generate ./cmd/jsonencgen/...")
+}
+
+func TestGenerate(t *testing.T) {
+	t.Run("keys", func(t *testing.T) {
+		code, err := Generate("a.go", []byte(`package a
+
+//jsonenc:generate
+type A struct {
+	X, Y uint8
+	Z    float32 `+"`json:\"z-value\"`"+`
+}
+`))
+		assert.NoError(t, err)
+		assert.Contains(t, string(code), "jsonencA_z_2d_value = encoder.NewKey(\"z-value\")")
+		assert.Contains(t, string(code), `	enc.ObjectStart()
+	enc.Key(jsonencA_X)
+	enc.Uint32(uint32(v.X))
+	enc.Key(jsonencA_Y)
+	enc.Uint32(uint32(v.Y))
+	enc.Key(jsonencA_z_2d_value)
+	enc.Float64(float64(v.Z))
+	enc.ObjectEnd()
+`)
+	})
+
+	t.Run("key names", func(t *testing.T) {
+		code, err := Generate("a.go", []byte(`package a
+
+//jsonenc:generate
+type A struct {
+	X uint8 `+"`json:\"a-b\"`"+`
+	Y uint8 `+"`json:\"a_2d_b\"`"+`
+}
+
+//jsonenc:generate
+type A_a struct {
+	Z uint8 `+"`json:\"2d_b\"`"+`
+}
+`))
+		assert.NoError(t, err)
+		assert.Contains(t, string(code), "jsonencA_a_2d_b   = encoder.NewKey(\"a-b\")")
+		assert.Contains(t, string(code), "jsonencA_a_2d_b_2 = encoder.NewKey(\"a_2d_b\")")
+		assert.Contains(t, string(code), "jsonencA_a_2d_b_3 = encoder.NewKey(\"2d_b\")")
+		assert.Contains(t, string(code), "enc.Key(jsonencA_a_2d_b_2)\n\tenc.Uint32(uint32(v.Y))")
+	})
+
+	t.Run("string option", func(t *testing.T) {
+		code, err := Generate("a.go", []byte(`package a
+
+//jsonenc:generate
+type A struct {
+	S string  `+"`json:\"s,string\"`"+`
+	B bool    `+"`json:\"b,string\"`"+`
+	F float64 `+"`json:\"f,string\"`"+`
+}
+`))
+		assert.NoError(t, err)
+		assert.Contains(t, string(code), "enc.EncodedStr(v.S)")
+		assert.Contains(t, string(code), "enc.EncodedBool(v.B)")
+		assert.Contains(t, string(code), "enc.EncodedFloat64(v.F)")
+	})
+
+	t.Run("grouped", func(t *testing.T) {
+		code, err := Generate("a.go", []byte(`package a
+
+type (
+	//jsonenc:generate
+	A struct{ T uint32 `+"`json:\"t,timestamp,omitempty\"`"+` }
+	B struct{}
+)
+`))
+		assert.NoError(t, err)
+		assert.Contains(t, string(code), `	if v.T != 0 {
+		enc.Key(jsonencA_t)
+		enc.Timestamp(v.T)
+	}
+`)
+		assert.NotContains(t, string(code), "*B")
+	})
+
+	t.Run("none", func(t *testing.T) {
+		code, err := Generate("a.go", []byte("package a\n\ntype A struct{}\n"))
+		assert.NoError(t, err)
+		assert.Nil(t, code)
+	})
+
+	errs := map[string]string{
+		"embedded":  "type B struct{}\n\n//jsonenc:generate\ntype A struct{ B }\n",
+		"duplicate": "//jsonenc:generate\ntype A struct{ X int; Y int `json:\"X\"` }\n",
+		"timestamp": "//jsonenc:generate\ntype A struct{ X uint64 `json:\"x,timestamp\"` }\n",
+		"syntax":    "type A struct{",
+	}
+	for name, src := range errs {
+		t.Run(name, func(t *testing.T) {
+			_, err := Generate("a.go", []byte("package a\n\n"+src))
+			assert.Error(t, err)
+		})
+	}
+}
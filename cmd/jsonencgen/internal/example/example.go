@@ -0,0 +1,52 @@
+// Package example holds structs encoded by methods generated with
+// jsonencgen. The generated file is kept up to date by the jsonencgen tests.
+package example
+
+import (
+	"database/sql"
+	"time"
+)
+
+//go:generate go run github.com/simook/jsonencoder/cmd/jsonencgen
+
+// Sample is a single measurement.
+//
+//jsonenc:generate
+type Sample struct {
+	Timestamp uint32    `json:"timestamp,timestamp"`
+	Value     float64   `json:"value"`
+	Delta     int       `json:"delta"`
+	Count     uint64    `json:"count,string"`
+	Unit      string    `json:"unit"`
+	Valid     bool      `json:"valid"`
+	Seen      time.Time `json:"seen"`
+	Location  *Location `json:"location"`
+	internal  int
+}
+
+// Series is a named list of values.
+//
+//jsonenc:generate
+type Series struct {
+	Name   string         `json:"name"`
+	Values []float64      `json:"values,omitempty"`
+	Tags   []string       `json:"tags,omitempty"`
+	Min    *float64       `json:"min,omitempty"`
+	Note   sql.NullString `json:"note"`
+	Skip   string         `json:"-"`
+	Last   Sample         `json:"last"`
+	Extra  map[string]int `json:"extra,omitempty"`
+}
+
+// Location is written by its generated method when referenced by Sample.
+//
+//jsonenc:generate
+type Location struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// Unannotated has no generated method.
+type Unannotated struct {
+	A int
+}
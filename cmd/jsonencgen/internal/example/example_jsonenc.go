@@ -0,0 +1,91 @@
+// Code generated by jsonencgen; DO NOT EDIT.
+
+package example
+
+import encoder "github.com/simook/jsonencoder"
+
+var (
+	jsonencSample_timestamp = encoder.NewKey("timestamp")
+	jsonencSample_value     = encoder.NewKey("value")
+	jsonencSample_delta     = encoder.NewKey("delta")
+	jsonencSample_count     = encoder.NewKey("count")
+	jsonencSample_unit      = encoder.NewKey("unit")
+	jsonencSample_valid     = encoder.NewKey("valid")
+	jsonencSample_seen      = encoder.NewKey("seen")
+	jsonencSample_location  = encoder.NewKey("location")
+)
+
+// EncodeJSON writes Sample as a JSON object.
+func (v *Sample) EncodeJSON(enc *encoder.Encoder) {
+	enc.ObjectStart()
+	enc.Key(jsonencSample_timestamp)
+	enc.Timestamp(v.Timestamp)
+	enc.Key(jsonencSample_value)
+	enc.Float64(v.Value)
+	enc.Key(jsonencSample_delta)
+	enc.Int64(int64(v.Delta))
+	enc.Key(jsonencSample_count)
+	enc.EncodedUint64(v.Count)
+	enc.Key(jsonencSample_unit)
+	enc.Str(v.Unit)
+	enc.Key(jsonencSample_valid)
+	enc.Bool(v.Valid)
+	enc.Key(jsonencSample_seen)
+	enc.Time(v.Seen)
+	enc.Key(jsonencSample_location)
+	enc.Value(v.Location)
+	enc.ObjectEnd()
+}
+
+var (
+	jsonencSeries_name   = encoder.NewKey("name")
+	jsonencSeries_values = encoder.NewKey("values")
+	jsonencSeries_tags   = encoder.NewKey("tags")
+	jsonencSeries_min    = encoder.NewKey("min")
+	jsonencSeries_note   = encoder.NewKey("note")
+	jsonencSeries_last   = encoder.NewKey("last")
+	jsonencSeries_extra  = encoder.NewKey("extra")
+)
+
+// EncodeJSON writes Series as a JSON object.
+func (v *Series) EncodeJSON(enc *encoder.Encoder) {
+	enc.ObjectStart()
+	enc.Key(jsonencSeries_name)
+	enc.Str(v.Name)
+	if len(v.Values) > 0 {
+		enc.Key(jsonencSeries_values)
+		enc.Float64Array(v.Values)
+	}
+	if len(v.Tags) > 0 {
+		enc.Key(jsonencSeries_tags)
+		enc.StringArray(v.Tags)
+	}
+	if v.Min != nil {
+		enc.Key(jsonencSeries_min)
+		enc.Float64Ptr(v.Min)
+	}
+	enc.Key(jsonencSeries_note)
+	enc.NullString(v.Note)
+	enc.Key(jsonencSeries_last)
+	enc.Value(&v.Last)
+	if len(v.Extra) > 0 {
+		enc.Key(jsonencSeries_extra)
+		enc.Encode(v.Extra)
+	}
+	enc.ObjectEnd()
+}
+
+var (
+	jsonencLocation_lat = encoder.NewKey("lat")
+	jsonencLocation_lon = encoder.NewKey("lon")
+)
+
+// EncodeJSON writes Location as a JSON object.
+func (v *Location) EncodeJSON(enc *encoder.Encoder) {
+	enc.ObjectStart()
+	enc.Key(jsonencLocation_lat)
+	enc.Float64(v.Lat)
+	enc.Key(jsonencLocation_lon)
+	enc.Float64(v.Lon)
+	enc.ObjectEnd()
+}
@@ -0,0 +1,82 @@
+package example
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	encoder "github.com/simook/jsonencoder"
+	"github.com/stretchr/testify/assert"
+)
+
+func encode(t *testing.T, v encoder.EncoderMarshaler) string {
+	var b bytes.Buffer
+	enc := encoder.GetWriterEncoder(&b)
+	defer enc.Release()
+
+	enc.SetConfig(encoder.EncoderConfig{UTCTimestamps: true, Strict: true})
+	enc.Value(v)
+	assert.NoError(t, enc.Close())
+	assert.True(t, json.Valid(b.Bytes()), b.String())
+	return b.String()
+}
+
+func TestSampleEncodeJSON(t *testing.T) {
+	s := Sample{
+		Timestamp: 1700000000,
+		Value:     1.5,
+		Delta:     -3,
+		Count:     9007199254740993,
+		Unit:      `"C"`,
+		Valid:     true,
+		Seen:      time.Unix(1700000000, 0),
+		Location:  &Location{Lat: 52.5, Lon: 13.4},
+	}
+	assert.Equal(t, `{"timestamp":"2023-11-14T22:13:20+00","value":1.5,"delta":-3,`+
+		`"count":"9007199254740993","unit":"\"C\"","valid":true,`+
+		`"seen":"2023-11-14T22:13:20+00","location":{"lat":52.5,"lon":13.4}}`, encode(t, &s))
+
+	s.Location = nil
+	assert.Contains(t, encode(t, &s), `"location":null}`)
+}
+
+func TestSeriesEncodeJSON(t *testing.T) {
+	t.Run("omitempty", func(t *testing.T) {
+		assert.Equal(t, `{"name":"","note":null,"last":{"timestamp":"1970-01-01T00:00:00+00",`+
+			`"value":0,"delta":0,"count":"0","unit":"","valid":false,`+
+			`"seen":"0001-01-01T00:00:00+00","location":null}}`, encode(t, &Series{}))
+	})
+
+	t.Run("full", func(t *testing.T) {
+		min := 0.25
+		s := Series{
+			Name:   "temp",
+			Values: []float64{0.25, 1},
+			Tags:   []string{"a", "b"},
+			Min:    &min,
+			Note:   sql.NullString{String: "n", Valid: true},
+			Skip:   "skip",
+			Extra:  map[string]int{"b": 2, "a": 1},
+		}
+		out := encode(t, &s)
+		assert.Contains(t, out, `{"name":"temp","values":[0.25,1],"tags":["a","b"],"min":0.25,"note":"n","last":{`)
+		assert.Contains(t, out, `},"extra":{"a":1,"b":2}}`)
+		assert.NotContains(t, out, "skip")
+	})
+}
+
+func BenchmarkSampleEncodeJSON(b *testing.B) {
+	enc := encoder.GetWriterEncoder(io.Discard)
+	defer enc.Release()
+
+	s := Sample{Timestamp: 1700000000, Value: 1.5, Delta: -3, Count: 7, Unit: "C", Location: &Location{}}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		s.EncodeJSON(enc)
+		enc.Write()
+	}
+}
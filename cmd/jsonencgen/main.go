@@ -0,0 +1,80 @@
+// Command jsonencgen generates EncodeJSON methods for structs, so they can be
+// written with the Encoder without hand-writing or hand-maintaining each
+// encoder.
+//
+// Annotate the structs with a //jsonenc:generate comment and add a go:generate
+// directive to the file:
+//
+//	//go:generate go run github.com/simook/jsonencoder/cmd/jsonencgen
+//
+//	//jsonenc:generate
+//	type Point struct {
+//		Timestamp uint32  `json:"timestamp,timestamp"`
+//		X         float64 `json:"x"`
+//		Y         float64 `json:"y,omitempty"`
+//	}
+//
+// go generate then writes point_jsonenc.go next to point.go, holding
+//
+//	func (v *Point) EncodeJSON(enc *encoder.Encoder)
+//
+// which writes each field with a Key made by NewKey, followed by Timestamp,
+// Float64, etc. The json struct tags are honoured (name, omitempty, string and
+// -), and the timestamp option writes a uint32 unix timestamp with Timestamp.
+// Fields of types the generator does not know are written with Value if they
+// are annotated structs of the same file, and with the reflection based Encode
+// otherwise.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	output := flag.String("output", "", "output file name; default <file>_jsonenc.go")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: jsonencgen [-output file] [file.go]\n")
+		fmt.Fprintf(os.Stderr, "the file defaults to $GOFILE, as set by go generate.\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	file := flag.Arg(0)
+	if file == "" {
+		file = os.Getenv("GOFILE")
+	}
+	if file == "" || flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *output == "" {
+		*output = strings.TrimSuffix(file, filepath.Ext(file)) + "_jsonenc.go"
+	}
+
+	if err := run(file, *output); err != nil {
+		fmt.Fprintf(os.Stderr, "jsonencgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(file, output string) error {
+	src, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	code, err := Generate(file, src)
+	if err != nil {
+		return err
+	}
+	if code == nil {
+		return fmt.Errorf("%s: no //jsonenc:generate structs found", file)
+	}
+
+	return os.WriteFile(output, code, 0o644)
+}
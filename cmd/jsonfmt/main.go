@@ -0,0 +1,158 @@
+// Command jsonfmt formats JSON with the pretty printer of the encoder package.
+//
+// It reads the files named on the command line, or the standard input, and
+// writes them to the standard output, indented by default:
+//
+//	jsonfmt [-indent n | -tab] [-width n] [-color mode] [-compact] [-sort] [file ...]
+//
+// A file may hold a stream of values, i.e. NDJSON, which are written one after
+// the other. The JSON is streamed through the encoder in small chunks, so files
+// of any size are formatted in constant memory. -sort is the exception: it
+// sorts the keys of each top-level value, which is held in memory to do so.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	encoder "github.com/simook/jsonencoder"
+)
+
+// options configure how the JSON is formatted.
+type options struct {
+	compact bool // write the JSON without whitespace.
+	sort    bool // sort the keys of objects.
+	config  encoder.EncoderConfig
+}
+
+func main() {
+	indent := flag.Int("indent", 4, "number of spaces to indent with")
+	tab := flag.Bool("tab", false, "indent with tabs")
+	width := flag.Int("width", 0, "keep arrays and objects of scalars within this width on one line")
+	color := flag.String("color", "auto", "color the output: auto, always or never")
+	compact := flag.Bool("compact", false, "write compact JSON, one value per line")
+	sort := flag.Bool("sort", false, "sort object keys")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: jsonfmt [flags] [file ...]\n")
+		fmt.Fprintf(os.Stderr, "the files default to the standard input.\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	opt := options{
+		compact: *compact,
+		sort:    *sort,
+		config: encoder.EncoderConfig{
+			IndentString:    strings.Repeat(" ", *indent),
+			MaxWidth:        *width,
+			TrailingNewline: true,
+		},
+	}
+	if *indent == 0 {
+		opt.config.EmptyIndent = true
+	}
+	if *tab {
+		opt.config.IndentString = "\t"
+	}
+
+	switch *color {
+	case "auto":
+		opt.config.Color = encoder.COLOR_AUTO
+	case "always":
+		opt.config.Color = encoder.COLOR_ALWAYS
+	case "never":
+		opt.config.Color = encoder.COLOR_NEVER
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *indent < 0 || *width < 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	files := flag.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	code := 0
+	for _, file := range files {
+		if err := runFile(os.Stdout, file, &opt); err != nil {
+			fmt.Fprintf(os.Stderr, "jsonfmt: %s: %v\n", file, err)
+			code = 1
+		}
+	}
+	os.Exit(code)
+}
+
+// runFile formats the file, or the standard input if it is "-".
+func runFile(dst io.Writer, file string, opt *options) error {
+	if file == "-" {
+		return run(dst, os.Stdin, opt)
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return run(dst, f, opt)
+}
+
+// run formats the JSON read from src to dst.
+func run(dst io.Writer, src io.Reader, opt *options) error {
+	if opt.sort {
+		src = newSortReader(src)
+	}
+
+	if !opt.compact {
+		return encoder.Indent(dst, src, opt.config)
+	}
+
+	if err := encoder.Compact(dst, src); err != nil {
+		return err
+	}
+	_, err := io.WriteString(dst, "\n")
+	return err
+}
+
+// sortReader reads the JSON of another reader with the keys of its objects
+// sorted. The values are decoded one at a time, so the memory held is bounded
+// by the largest top-level value.
+type sortReader struct {
+	dec *json.Decoder
+	enc *json.Encoder
+	buf bytes.Buffer
+}
+
+func newSortReader(src io.Reader) *sortReader {
+	r := &sortReader{dec: json.NewDecoder(src)}
+	// keep numbers as written.
+	r.dec.UseNumber()
+	r.enc = json.NewEncoder(&r.buf)
+	r.enc.SetEscapeHTML(false)
+	return r
+}
+
+func (r *sortReader) Read(p []byte) (int, error) {
+	for r.buf.Len() == 0 {
+		var v interface{}
+		if err := r.dec.Decode(&v); err != nil {
+			return 0, err
+		}
+
+		// maps are encoded with sorted keys.
+		if err := r.enc.Encode(v); err != nil {
+			return 0, err
+		}
+	}
+	return r.buf.Read(p)
+}
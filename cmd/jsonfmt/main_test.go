@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	encoder "github.com/simook/jsonencoder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	format := func(src string, opt options) string {
+		var b bytes.Buffer
+		assert.NoError(t, run(&b, strings.NewReader(src), &opt))
+		return b.String()
+	}
+
+	indent := encoder.EncoderConfig{IndentString: "  ", TrailingNewline: true}
+
+	t.Run("indent", func(t *testing.T) {
+		got := format(`{"b": [1, 2], "a": {}}`, options{config: indent})
+		assert.Equal(t, "{\n  \"b\": [\n    1,\n    2\n  ],\n  \"a\": {}\n}\n", got)
+	})
+
+	t.Run("tab and width", func(t *testing.T) {
+		got := format(`{"b": [1, 2], "a": {}}`, options{config: encoder.EncoderConfig{IndentString: "\t", MaxWidth: 80, TrailingNewline: true}})
+		assert.Equal(t, "{\n\t\"b\": [1, 2],\n\t\"a\": {}\n}\n", got)
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		got := format("{\"a\":1}\n\"b\"\n[]\n", options{config: indent})
+		assert.Equal(t, "{\n  \"a\": 1\n}\n\"b\"\n[]\n", got)
+
+		got = format("{ \"a\" : 1 }\n\"b\"\n[ ]\n", options{compact: true})
+		assert.Equal(t, "{\"a\":1}\n\"b\"\n[]\n", got)
+	})
+
+	t.Run("sort", func(t *testing.T) {
+		src := `{"b": {"d": 1.50, "c": "<&>"}, "a": [{"z": 1, "y": 2}]}` + "\n" + `{"y": 1, "x": 2}`
+		got := format(src, options{compact: true, sort: true})
+		assert.Equal(t, `{"a":[{"y":2,"z":1}],"b":{"c":"<&>","d":1.50}}`+"\n"+`{"x":2,"y":1}`+"\n", got)
+	})
+
+	t.Run("color", func(t *testing.T) {
+		got := format(`[true]`, options{config: encoder.EncoderConfig{Color: encoder.COLOR_ALWAYS}})
+		assert.Equal(t, "\x1b[1m[\x1b[0m\n    \x1b[35mtrue\x1b[0m\n\x1b[1m]\x1b[0m", got)
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		var b bytes.Buffer
+		err := run(&b, strings.NewReader(`{"a": tru}`), &options{config: indent})
+		assert.EqualError(t, err, "encoder: invalid character '}' in literal true (expecting 'e') at offset 9")
+
+		err = run(&b, strings.NewReader(`{"a": tru}`), &options{sort: true, config: indent})
+		assert.Error(t, err)
+	})
+}
+
+func TestRunFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "a.json")
+	assert.NoError(t, os.WriteFile(file, []byte(`[1,2]`), 0o644))
+
+	var b bytes.Buffer
+	assert.NoError(t, runFile(&b, file, &options{compact: true}))
+	assert.Equal(t, "[1,2]\n", b.String())
+
+	assert.Error(t, runFile(&b, filepath.Join(t.TempDir(), "missing.json"), &options{}))
+}
@@ -0,0 +1,59 @@
+package encoder
+
+import (
+	"io"
+	"os"
+)
+
+const (
+	COLOR_NEVER  = 0
+	COLOR_AUTO   = 1
+	COLOR_ALWAYS = 2
+)
+
+// colorReset ends a colored token.
+const colorReset = "\x1b[0m"
+
+// Palette holds the ANSI escape sequences PrettyPrint writes in front of each
+// kind of token, i.e. "\x1b[32m" for green. An empty sequence leaves the
+// token uncolored.
+type Palette struct {
+	Key         string // object keys.
+	String      string // string values.
+	Number      string
+	Literal     string // true, false and null.
+	Punctuation string // braces, brackets, commas and colons.
+}
+
+// DefaultPalette is used if EncoderConfig.Palette is nil.
+var DefaultPalette = Palette{
+	Key:         "\x1b[34;1m",
+	String:      "\x1b[32m",
+	Number:      "\x1b[36m",
+	Literal:     "\x1b[35m",
+	Punctuation: "\x1b[1m",
+}
+
+// palette returns the palette to color the pretty print with, or nil if it
+// is not colored. tty reports whether the writer is a terminal.
+func (c *EncoderConfig) palette(tty bool) *Palette {
+	if c.Color == COLOR_NEVER || c.Color == COLOR_AUTO && !tty {
+		return nil
+	}
+
+	if c.Palette != nil {
+		return c.Palette
+	}
+	return &DefaultPalette
+}
+
+// isTerminal reports whether w is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,74 @@
+package encoder
+
+import (
+	"bytes"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// markers is a palette which is readable in tests.
+var markers = Palette{Key: "<k>", String: "<s>", Number: "<n>", Literal: "<l>", Punctuation: "<p>"}
+
+// ansi matches ANSI color sequences.
+var ansi = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+func TestEncoderPrettyPrintColor(t *testing.T) {
+	readable := func(s string) string {
+		return strings.ReplaceAll(s, colorReset, "</>")
+	}
+
+	t.Run("palette", func(t *testing.T) {
+		got := prettyPrint(t, EncoderConfig{Color: COLOR_ALWAYS, Palette: &markers, IndentString: " "},
+			`{"a":[1,"x\"",true],"b":null}`)
+		assert.Equal(t, "<p>{</>\n"+
+			` <k>"a"</><p>:</> <p>[</>`+"\n"+
+			`  <n>1</><p>,</>`+"\n"+
+			`  <s>"x\""</><p>,</>`+"\n"+
+			`  <l>true</>`+"\n"+
+			` <p>]</><p>,</>`+"\n"+
+			` <k>"b"</><p>:</> <l>null</>`+"\n"+
+			"<p>}</>", readable(got))
+	})
+
+	t.Run("split", func(t *testing.T) {
+		got := prettyPrint(t, EncoderConfig{Color: COLOR_ALWAYS, Palette: &markers, MaxWidth: 80},
+			`{"ke`, `y":-1`, `2.5,"v":"a\`, `"b"}`)
+		assert.Equal(t, `<p>{</><k>"key"</><p>:</> <n>-12.5</><p>,</> <k>"v"</><p>:</> <s>"a\"b"</><p>}</>`, readable(got))
+	})
+
+	t.Run("top-level scalar", func(t *testing.T) {
+		got := prettyPrint(t, EncoderConfig{Color: COLOR_ALWAYS, Palette: &markers}, `12`)
+		assert.Equal(t, `<n>12</>`, readable(got))
+	})
+
+	t.Run("empty colors", func(t *testing.T) {
+		got := prettyPrint(t, EncoderConfig{Color: COLOR_ALWAYS, Palette: &Palette{Literal: "<l>"}}, `[1e5,"a",true]`)
+		assert.Equal(t, "[\n    1e5,\n    \"a\",\n    <l>true</>\n]", readable(got))
+	})
+
+	t.Run("default palette", func(t *testing.T) {
+		src := `{"a":[1,"b",false],"c":{"d":null}}`
+		got := prettyPrint(t, EncoderConfig{Color: COLOR_ALWAYS, MaxWidth: 20}, src)
+		assert.Contains(t, got, DefaultPalette.Key+`"a"`+colorReset)
+		assert.Equal(t, prettyPrint(t, EncoderConfig{MaxWidth: 20}, src), ansi.ReplaceAllString(got, ""))
+	})
+
+	t.Run("auto", func(t *testing.T) {
+		assert.Equal(t, "[\n    1\n]", prettyPrint(t, EncoderConfig{Color: COLOR_AUTO}, `[1]`))
+
+		f, err := os.CreateTemp(t.TempDir(), "color")
+		assert.NoError(t, err)
+		defer f.Close()
+		assert.False(t, isTerminal(f))
+		assert.False(t, isTerminal(new(bytes.Buffer)))
+	})
+
+	t.Run("never", func(t *testing.T) {
+		got := prettyPrint(t, EncoderConfig{Palette: &markers}, `[1]`)
+		assert.Equal(t, "[\n    1\n]", got)
+	})
+}
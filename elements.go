@@ -0,0 +1,99 @@
+package encoder
+
+import (
+	"time"
+)
+
+// The element writers write bare values, i.e. array elements, without a key.
+// Delimiters are managed by the Encoder.
+//
+//	enc.ArrayStart()
+//	enc.Uint64(1)
+//	enc.Uint64(2)
+//	enc.ArrayEnd()
+
+// Uint32 writes the value as an array element.
+func (enc *Encoder) Uint32(value uint32) {
+	enc.writeUint64(uint64(value), false)
+}
+
+// EncodedUint32 writes the quoted value as an array element.
+func (enc *Encoder) EncodedUint32(value uint32) {
+	enc.writeUint64(uint64(value), true)
+}
+
+// Uint64 writes the value as an array element.
+func (enc *Encoder) Uint64(value uint64) {
+	enc.writeUint64(value, false)
+}
+
+// EncodedUint64 writes the quoted value as an array element.
+func (enc *Encoder) EncodedUint64(value uint64) {
+	enc.writeUint64(value, true)
+}
+
+// Int32 writes the value as an array element.
+func (enc *Encoder) Int32(value int32) {
+	enc.writeInt64(int64(value), false)
+}
+
+// EncodedInt32 writes the quoted value as an array element.
+func (enc *Encoder) EncodedInt32(value int32) {
+	enc.writeInt64(int64(value), true)
+}
+
+// Int64 writes the value as an array element.
+func (enc *Encoder) Int64(value int64) {
+	enc.writeInt64(value, false)
+}
+
+// EncodedInt64 writes the quoted value as an array element.
+func (enc *Encoder) EncodedInt64(value int64) {
+	enc.writeInt64(value, true)
+}
+
+// Float64 writes the value, rounded if configured, as an array element.
+func (enc *Encoder) Float64(value float64) {
+	enc.writeFloat64(value, false)
+}
+
+// EncodedFloat64 writes the quoted value, rounded if configured, as an array
+// element.
+func (enc *Encoder) EncodedFloat64(value float64) {
+	enc.writeFloat64(value, true)
+}
+
+// StrBytes writes the escaped value as an array element.
+func (enc *Encoder) StrBytes(value []byte) {
+	writeString(enc, value)
+}
+
+// Str writes the escaped value as an array element.
+func (enc *Encoder) Str(value string) {
+	writeString(enc, value)
+}
+
+// EncodedStr writes the value encoded as a JSON string, then quoted again as
+// an array element, i.e. "\"a\"".
+func (enc *Encoder) EncodedStr(value string) {
+	enc.beginValue()
+	if enc.ok() {
+		enc.b.WriteByte(quoteMark)
+		enc.b.WriteString(`\"`)
+		enc.esc = appendEscaped(enc.esc[:0], value, enc.c.PreserveNewlines)
+		writeEscaped(enc, enc.esc)
+		enc.b.WriteString(`\"`)
+		enc.b.WriteByte(quoteMark)
+	}
+	enc.endValue()
+}
+
+// Timestamp writes the unix timestamp as an ISO8601 array element.
+func (enc *Encoder) Timestamp(value uint32) {
+	enc.writeUint32Timestamp(value)
+}
+
+// Time writes the time as an ISO8601 array element.
+func (enc *Encoder) Time(value time.Time) {
+	enc.writeTime(value)
+}
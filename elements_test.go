@@ -0,0 +1,84 @@
+package encoder
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncoderElements(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	enc.c.UTCTimestamps = true
+
+	enc.ArrayStart()
+	enc.Uint32(1)
+	enc.EncodedUint32(2)
+	enc.Uint64(3)
+	enc.EncodedUint64(4)
+	enc.Int32(-5)
+	enc.EncodedInt32(-6)
+	enc.Int64(-7)
+	enc.EncodedInt64(-8)
+	enc.Float64(0.00010732467532467535)
+	enc.EncodedFloat64(0.5)
+	enc.StrBytes([]byte("a"))
+	enc.Str(`"b"`)
+	enc.EncodedStr(`"c"`)
+	enc.Bool(true)
+	enc.EncodedBool(false)
+	enc.Null()
+	enc.Timestamp(0)
+	enc.Time(time.Unix(0, 0))
+	enc.ArrayEnd()
+
+	assert.Equal(t, `[1,"2",3,"4",-5,"-6",-7,"-8",0.000107,"0.5","a","\"b\"","\"\\\"c\\\"\"",true,"false",null,`+
+		`"1970-01-01T00:00:00+00","1970-01-01T00:00:00+00"]`, enc.b.String())
+}
+
+func TestEncoderNestedElements(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+
+	enc.ArrayStart()
+	enc.ArrayStart()
+	enc.Uint64(1)
+	enc.Uint64(2)
+	enc.ArrayEnd()
+	enc.ObjectStart()
+	enc.Uint64Field([]byte("a"), 3)
+	enc.ObjectEnd()
+	enc.Uint64(4)
+	enc.ArrayEnd()
+
+	assert.Equal(t, `[[1,2],{"a":3},4]`, enc.b.String())
+}
+
+func TestEncoderElementsValid(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+
+	enc.ArrayStart()
+	for i := 0; i < 100; i++ {
+		enc.Float64(float64(i) / 3)
+	}
+	enc.ArrayEnd()
+
+	var values []float64
+	assert.NoError(t, json.Unmarshal(enc.Bytes(), &values))
+	assert.Len(t, values, 100)
+}
+
+func BenchmarkEncoderUint64(b *testing.B) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	enc.ArrayStart()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		enc.b.Reset()
+		enc.Uint64(uint64(i))
+	}
+}
@@ -59,25 +59,92 @@ func Logf(format string, v ...interface{}) {
 
 type Encoder struct {
 	b                      *bytes.Buffer
-	n                      int64 // internal: number of bytes written.
-	f                      int64 // internal: number of writes to the pipe.
-	d                      int   // internal: pretty print depth.
-	s                      bool  // internal: pretty print string.
+	n                      int64    // internal: number of bytes written.
+	f                      int64    // internal: number of writes to the pipe.
+	d                      int      // internal: pretty print depth.
+	s                      bool     // internal: pretty print string.
+	e                      bool     // internal: pretty print escape in a string.
+	t                      bool     // internal: pretty print top-level value without trailing newline.
+	col                    int      // internal: pretty print column.
+	il                     []byte   // internal: pretty print container held back to write inline.
+	ic                     int      // internal: pretty print column of the held back container.
+	iw                     int      // internal: pretty print width of the held back container.
+	flat                   bool     // internal: pretty print the held back container on one line.
+	pk                     []byte   // internal: pretty print container kinds.
+	k                      bool     // internal: pretty print next string is a key, in an object.
+	v                      bool     // internal: pretty print colored token to be reset.
+	u                      bool     // internal: pretty print number or literal.
+	cl                     *Palette // internal: pretty print palette, if colored.
+	tty                    bool     // internal: w is a terminal.
+	st                     []frame  // internal: nesting state.
+	ptr                    int      // internal: reflection pointer and interface depth.
+	p                      []byte   // internal: strict mode key path.
+	esc                    []byte   // internal: Escape buffer.
 	recoveredPanicsCounter int64
 	encoderTimeoutsCounter int64
-	w                      *io.PipeWriter // pipe writer
+	err                    error      // internal: first error encountered.
+	w                      io.Writer  // underlying writer
+	closer                 io.Closer  // internal: w, if it is closed by Close().
+	flusher                flusher    // internal: w as an http.Flusher, if it is one.
+	bufFlusher             bufFlusher // internal: w as a bufio.Writer like flusher, if it is one.
 	c                      EncoderConfig
 	ctx                    context.Context
 	cancel                 context.CancelFunc
 }
 
+// flusher is implemented by writers which stream their output on demand,
+// i.e. http.Flusher.
+type flusher interface {
+	Flush()
+}
+
+// bufFlusher is implemented by buffered writers, i.e. bufio.Writer.
+type bufFlusher interface {
+	Flush() error
+}
+
 type EncoderConfig struct {
+	// Indent selects the indentation of PrettyPrint if IndentString is
+	// empty: 4 spaces (SPACE_MODE) or a tab (TAB_MODE) per depth.
 	Indent        int
 	Logging       bool
 	UTCTimestamps bool
 	Round         bool
 	Precision     int
 	Pretty        bool
+	// Strict rejects structurally invalid output: keys outside of objects,
+	// values without keys, unbalanced ends, misplaced delimiters and closing
+	// the Encoder with open objects or arrays. The first violation is
+	// recorded as a *SyntaxError and all further writes are dropped.
+	Strict bool
+	// PreserveNewlines escapes newlines in strings as \n. By default they
+	// are replaced with a space.
+	PreserveNewlines bool
+	// ValidateRaw validates and compacts the fragments written by WriteRaw
+	// and WriteRawKey. An invalid fragment is recorded as a *SyntaxError.
+	ValidateRaw bool
+	// IndentString is written once per depth to indent a line, i.e. "  ".
+	IndentString string
+	// EmptyIndent indents with an empty IndentString, i.e. not at all like
+	// json.MarshalIndent(v, prefix, ""), instead of falling back to Indent.
+	EmptyIndent bool
+	// Prefix is written at the beginning of every line but the first, like
+	// the prefix of json.Indent.
+	Prefix string
+	// OmitColonSpace omits the space PrettyPrint writes after a colon.
+	OmitColonSpace bool
+	// TrailingNewline ends every top-level value with a newline, like
+	// json.Encoder does.
+	TrailingNewline bool
+	// MaxWidth keeps arrays and objects which hold no other arrays or
+	// objects on one line if they fit within MaxWidth columns, i.e. 80. By
+	// default every element and member is written on a line of its own.
+	MaxWidth int
+	// Color colors PrettyPrint with ANSI escape sequences: COLOR_NEVER,
+	// COLOR_ALWAYS, or COLOR_AUTO to color only if the writer is a terminal.
+	Color int
+	// Palette holds the colors; if nil, DefaultPalette is used.
+	Palette *Palette
 }
 
 // NewEncoder initializes and returns a pointer to an Encoder.
@@ -98,6 +165,22 @@ func NewEncoder() *Encoder {
 // The given PipeWriter will be attached to the returned Encoder.
 // To return the Encoder back to the pool, call Release().
 func GetEncoder(w *io.PipeWriter) *Encoder {
+	if w == nil {
+		return GetWriterEncoder(nil)
+	}
+	return GetWriteCloserEncoder(w)
+}
+
+// GetWriterEncoder returns (or creates if none exists) an Encoder from the
+// pool, writing directly to w, i.e. a net.Conn, http.ResponseWriter,
+// bufio.Writer or *os.File.
+//
+// w is not closed by Close(), it belongs to the caller; see
+// GetWriteCloserEncoder. If w is an http.Flusher, it is flushed after every
+// write, and if w is a buffered writer with a Flush() error method, it is
+// flushed by Close().
+// To return the Encoder back to the pool, call Release().
+func GetWriterEncoder(w io.Writer) *Encoder {
 	enc := encPool.Get().(*Encoder)
 
 	// get a buffer from the pool
@@ -109,6 +192,19 @@ func GetEncoder(w *io.PipeWriter) *Encoder {
 
 	enc.ctx, enc.cancel = context.WithCancel(context.Background())
 	enc.w = w
+	enc.flusher, _ = w.(flusher)
+	enc.bufFlusher, _ = w.(bufFlusher)
+	enc.tty = isTerminal(w)
+	return enc
+}
+
+// GetWriteCloserEncoder returns an Encoder from the pool like
+// GetWriterEncoder, which closes w when the Encoder is closed, times out or
+// recovers a panic, i.e. to terminate the reader of a pipe.
+// To return the Encoder back to the pool, call Release().
+func GetWriteCloserEncoder(w io.WriteCloser) *Encoder {
+	enc := GetWriterEncoder(w)
+	enc.closer = w
 	return enc
 }
 
@@ -116,11 +212,29 @@ func GetEncoder(w *io.PipeWriter) *Encoder {
 // All internal structs and pointers are zeroed.
 func (enc *Encoder) Reset() {
 	enc.w = nil
+	enc.closer = nil
+	enc.flusher = nil
+	enc.bufFlusher = nil
 	enc.c.Reset()
 	enc.s = false
+	enc.e = false
+	enc.t = false
+	enc.col = 0
+	enc.il = enc.il[:0]
+	enc.flat = false
+	enc.pk = enc.pk[:0]
+	enc.k = false
+	enc.v = false
+	enc.u = false
+	enc.cl = nil
+	enc.tty = false
 	enc.d = 0
+	enc.ptr = 0
+	enc.st = enc.st[:0]
+	enc.p = enc.p[:0]
 	enc.n = 0
 	enc.f = 0
+	enc.err = nil
 	enc.b.Reset()
 	// garbage collect buffers that overflow MAXBUFSIZE.
 	if enc.b.Cap() <= MAXBUFSIZE {
@@ -137,13 +251,49 @@ func (c *EncoderConfig) Reset() {
 	c.Round = true
 	c.UTCTimestamps = false
 	c.Pretty = false
+	c.Strict = false
+	c.PreserveNewlines = false
+	c.ValidateRaw = false
+	c.IndentString = ""
+	c.EmptyIndent = false
+	c.Prefix = ""
+	c.OmitColonSpace = false
+	c.TrailingNewline = false
+	c.MaxWidth = 0
+	c.Color = COLOR_NEVER
+	c.Palette = nil
 }
 
 // Close the writer. Blocks until all writes have finished.
-func (enc *Encoder) Close() {
-	enc.Write()
+// Returns the first error encountered by the encoder, if any.
+func (enc *Encoder) Close() error {
+	if enc.c.Strict && len(enc.st) > 0 {
+		enc.fail("unclosed object or array")
+	}
+
+	enc.write()
+	if enc.c.Pretty {
+		enc.prettyEnd()
+	}
+	if enc.bufFlusher != nil && enc.ok() {
+		if err := enc.bufFlusher.Flush(); err != nil {
+			enc.setErr(err)
+		}
+	}
 	enc.cancel()
-	enc.w.Close()
+	if err := enc.closeWriter(); err != nil {
+		enc.setErr(err)
+	}
+	return enc.err
+}
+
+// closeWriter closes the underlying writer if the Encoder owns it, see
+// GetWriteCloserEncoder.
+func (enc *Encoder) closeWriter() error {
+	if enc.closer == nil {
+		return nil
+	}
+	return enc.closer.Close()
 }
 
 // Bytes returns the current buffer.
@@ -154,12 +304,49 @@ func (enc *Encoder) Bytes() []byte {
 // SetConfig sets the given config for the encoder.
 func (enc *Encoder) SetConfig(config EncoderConfig) {
 	enc.c = config
+	enc.cl = enc.c.palette(enc.tty)
 }
 
 func (enc *Encoder) Done() <-chan struct{} {
 	return enc.ctx.Done()
 }
 
+// Err returns the first error encountered by the encoder. Once an error has
+// been recorded, all further writes to the encoder are no-ops.
+func (enc *Encoder) Err() error {
+	return enc.err
+}
+
+// setErr records err if no error has been recorded yet.
+func (enc *Encoder) setErr(err error) {
+	if enc.err == nil {
+		enc.err = err
+	}
+}
+
+// ok reports whether the encoder still accepts writes: no error has been
+// recorded and the context has not been canceled.
+func (enc *Encoder) ok() bool {
+	if enc.err != nil {
+		return false
+	}
+
+	select {
+	case <-enc.Done():
+		return false
+	default:
+		return true
+	}
+}
+
+// WithContext derives the encoder context from ctx. Once ctx is canceled,
+// all further writes are dropped. WithContext needs to be called before
+// WithTimeout.
+func (enc *Encoder) WithContext(ctx context.Context) {
+	enc.cancel()
+	enc.ctx, enc.cancel = context.WithCancel(ctx)
+}
+
 func (enc *Encoder) WithTimeout(timeout time.Duration) {
 	go func() {
 		defer func() {
@@ -175,7 +362,7 @@ func (enc *Encoder) WithTimeout(timeout time.Duration) {
 			enc.encoderTimeoutsCounter++
 			Logf("Enc: timeout: %v", timeout)
 			enc.cancel()
-			enc.w.Close()
+			enc.closeWriter()
 			return
 		case <-enc.Done():
 			return
@@ -184,7 +371,7 @@ func (enc *Encoder) WithTimeout(timeout time.Duration) {
 }
 
 // flush will check the size of the buffer and if the size reaches MAXBUFSIZE,
-// it will write it to the underlying io.PipeWriter.
+// it will write it to the underlying writer.
 func (enc *Encoder) flush() {
 	if enc.Len() >= MAXBUFSIZE {
 		enc.write()
@@ -195,9 +382,9 @@ func (enc *Encoder) flush() {
 // method: defer enc.Release()
 //
 // Recovers any panics that occur during encoding. We don't want to crash the
-// server if any panics occur. Panics can occur when a pipe is closed as we
-// are writing to it, i.e. the client terminated the request or the server
-// terminated the connection.
+// server if any panics occur. Write errors, i.e. the client terminated the
+// request or the server terminated the connection, do not panic; they are
+// recorded and returned by Err() and Close().
 //
 // Returns the number of writes to the pipe, the number of bytes written,
 // and the buffer size in bytes.
@@ -207,8 +394,8 @@ func (enc *Encoder) Release() (int64, int64, int) {
 		// if TraceLogLevel() {
 		// 	Logf(TRACEENCODERPANIC, r)
 		// }
-		enc.cancel()  // cancel the context to stop any writers.
-		enc.w.Close() // close the writer to terminate the reader.
+		enc.cancel()      // cancel the context to stop any writers.
+		enc.closeWriter() // close the writer to terminate the reader.
 	}
 
 	cap := enc.b.Cap()
@@ -223,128 +410,51 @@ func (enc *Encoder) Release() (int64, int64, int) {
 	return f, n, cap
 }
 
-// Write the current encoder buffer to the pipe writer.
-func (enc *Encoder) Write() {
+// Write the current encoder buffer to the underlying writer.
+// Returns the first error encountered by the encoder, if any.
+func (enc *Encoder) Write() error {
 	enc.write()
+	return enc.err
 }
 
-// write the current buffer to the io.PipeWriter. when the write has finished
-// the buffer will be reset. if a Write to the PipeWriter fails, a panic will
-// be thrown.
+// write the current buffer to the underlying writer. when the write has
+// finished the buffer will be reset. if a Write to the writer fails, the error
+// is recorded and all further writes are dropped.
 func (enc *Encoder) write() {
-	if enc.b.Len() == 0 {
+	if enc.b.Len() == 0 || !enc.ok() {
 		return
 	}
 
-	select {
-	case <-enc.Done():
-		return
-	default:
-		if enc.c.Pretty {
-			enc.PrettyPrint()
-		}
-
-		// write the buffer
-		n, err := enc.w.Write(enc.b.Bytes())
-		if err != nil {
-			panic(err)
-		}
-
-		// reset the encoder buffer
-		enc.b.Reset()
-		enc.f++           // number of writes.
-		enc.n += int64(n) // number of bytes written.
+	if enc.c.Pretty {
+		enc.PrettyPrint()
 	}
-}
-
-// PrettyPrint will prettify the JSON in the encoder buffer.
-// It expects the buffer to have escaped strings and valid JSON.
-//
-// However, the buffer does not need the entire response object.
-// We are dependent upon the parent caller to provide a valid
-// json structure.
-func (enc *Encoder) PrettyPrint() {
-	// a buffer to write the pretty print.
-	buf := prettyPool.Get().(*bytes.Buffer)
-	buf.Reset()
-
-	if buf.Cap() <= MAXBUFSIZE {
-		buf.Grow(MAXBUFSIZE - buf.Cap())
-	}
-
-	defer func() {
-		// cleanup the buffer.
-		buf.Reset()
-		if buf.Cap() <= MAXBUFSIZE {
-			prettyPool.Put(buf)
-		}
-	}()
-
-	for {
-		c, err := enc.b.ReadByte()
-
-		if err != nil || c == 0 {
-			break
-		}
-
-		// todo: this can still break if the string contains quotes
-		if c == quoteMark {
-			enc.s = !enc.s
-		}
 
-		// if writing a string, do nothing
-		if enc.s {
-			buf.WriteByte(c)
-			continue
-		}
+	enc.writeBuffer()
+}
 
-		switch c {
-		case lBrace, lBracket: // {, [
-			enc.d++
-			buf.WriteByte(c)
-			enc.indentNewLine(buf)
-		case rBrace, rBracket: // }, ]
-			enc.d--
-			enc.indentNewLine(buf)
-			buf.WriteByte(c)
-		case delim: // ,
-			buf.WriteByte(c)
-			enc.indentNewLine(buf)
-		case colon: // ,
-			buf.WriteByte(c)
-			buf.WriteByte(space)
-		case quoteMark:
-			buf.WriteByte(c)
-		default:
-			buf.WriteByte(c)
-		}
+// writeBuffer writes the buffer as is to the underlying writer.
+func (enc *Encoder) writeBuffer() {
+	if enc.b.Len() == 0 || !enc.ok() {
+		return
 	}
 
-	if buf.Len() > 0 {
-		// ensure the encoder buffer is reset.
-		enc.b.Reset()
-		// write the pretty print buffer into the encoder buffer.
-		buf.WriteTo(enc.b)
-	}
-}
+	// write the buffer
+	n, err := enc.w.Write(enc.b.Bytes())
+	enc.f++           // number of writes.
+	enc.n += int64(n) // number of bytes written.
 
-func (enc *Encoder) indentNewLine(buf *bytes.Buffer) {
-	buf.WriteByte(newLine)
-	spaces := INDENT
+	// reset the encoder buffer
+	enc.b.Reset()
 
-	if enc.c.Indent != 0 {
-		spaces = 1
+	if err != nil {
+		enc.setErr(err)
+		return
 	}
 
-	for d := 0; d < enc.d*spaces; d++ {
-		switch enc.c.Indent {
-		case 1:
-			buf.WriteByte(tab)
-		default:
-			buf.WriteByte(space)
-		}
+	// push the written bytes to the client.
+	if enc.flusher != nil {
+		enc.flusher.Flush()
 	}
-
 }
 
 // Len returns the current size of the remaining encoding buffer.
@@ -352,85 +462,138 @@ func (enc *Encoder) Len() int {
 	return enc.b.Len()
 }
 
-// AppendByte adds a single byte to the buffer.
+// AppendByte adds a single byte to the buffer. The byte is taken into account
+// like the bytes of AppendBytes.
 func (enc *Encoder) AppendByte(value byte) {
-	select {
-	case <-enc.Done():
+	enc.appended([]byte{value})
+	enc.appendByte(value)
+}
+
+// appendByte adds a single byte to the buffer, leaving the nesting state
+// alone.
+func (enc *Encoder) appendByte(value byte) {
+	if !enc.ok() {
 		return
-	default:
-		enc.b.WriteByte(value)
 	}
+
+	enc.b.WriteByte(value)
 }
 
-// AppendBytes adds a byte slice to the buffer.
+// AppendBytes adds a byte slice to the buffer. The Encoder takes the bytes
+// into account: bytes ending with a colon are a key, bytes ending with a
+// delimiter are written like Delim writes it, and any other bytes are (a part
+// of) a value, so the next member or element is preceded by a delimiter.
 func (enc *Encoder) AppendBytes(value []byte) {
-	select {
-	case <-enc.Done():
+	enc.appended(value)
+	enc.appendBytes(value)
+}
+
+// appendBytes adds a byte slice to the buffer, leaving the nesting state
+// alone.
+func (enc *Encoder) appendBytes(value []byte) {
+	if !enc.ok() {
 		return
-	default:
-		enc.b.Write(value)
-		enc.flush()
+	}
+
+	enc.b.Write(value)
+	enc.flush()
+}
+
+// appended updates the nesting state for the bytes written by AppendByte and
+// AppendBytes.
+func (enc *Encoder) appended(value []byte) {
+	n := len(value)
+	if n == 0 {
+		return
+	}
+
+	if f := enc.top(); f != nil && (n > 1 || value[0] != delim) {
+		f.key = value[n-1] == colon
+		f.comma = !f.key
+		f.delim = false
+	}
+	if value[n-1] == delim {
+		enc.delimited()
 	}
 }
 
+// ObjectKey writes the quoted key followed by a colon. A delimiter is
+// inserted first if the previous member has not been followed by one.
 func (enc *Encoder) ObjectKey(value []byte) {
-	enc.EncodeKey(value)
-	enc.AppendByte(colon)
+	writeObjectKey(enc, value)
 }
 
-// Escape will check every byte in the slice for characters that need to be
-// escaped.
+// writeObjectKey writes the quoted and escaped key followed by a colon.
+func writeObjectKey[T []byte | string](enc *Encoder, key T) {
+	beginKey(enc, key)
+	enc.appendByte(quoteMark)
+	writeEscaped(enc, key)
+	enc.appendByte(quoteMark)
+	enc.appendByte(colon)
+}
+
+// Escape returns the value escaped as per RFC 8259.
 //
-// \ => "\\"
-// \n => " "
+// " => \"
+// \ => \\
+// \n => " " (or \n with EncoderConfig.PreserveNewlines)
+// \r, \t, \b, \f => \r, \t, \b, \f
+// other control characters => \u00XX
+//
+// The returned slice is reused by the Encoder; it is only valid until the
+// next call to Escape.
 func (enc *Encoder) Escape(value []byte) []byte {
-	buf := bufPool.Get().(*bytes.Buffer)
-	buf.Reset()
-
-	defer func() {
-		buf.Reset()
-		bufPool.Put(buf)
-	}()
-
-	for _, c := range value {
-		switch c {
-		case newLine:
-			buf.WriteByte(space)
-		case backslash:
-			buf.WriteByte(backslash)
-			buf.WriteByte(backslash)
-		default:
-			buf.WriteByte(c)
-		}
-	}
-
-	return buf.Bytes()
+	enc.esc = appendEscaped(enc.esc[:0], value, enc.c.PreserveNewlines)
+	return enc.esc
 }
 
 func (enc *Encoder) ObjectStart() {
-	enc.AppendByte(lBrace)
+	enc.beginValue()
+	enc.push(lBrace)
+	enc.appendByte(lBrace)
 }
 
 func (enc *Encoder) ObjectEnd() {
-	enc.AppendByte(rBrace)
+	enc.end(lBrace)
+	enc.appendByte(rBrace)
+	enc.endValue()
 }
 
 func (enc *Encoder) ArrayStart() {
-	enc.AppendByte(lBracket)
+	enc.beginValue()
+	enc.push(lBracket)
+	enc.appendByte(lBracket)
 }
 
 func (enc *Encoder) ArrayEnd() {
-	enc.AppendByte(rBracket)
+	enc.end(lBracket)
+	enc.appendByte(rBracket)
+	enc.endValue()
 }
 
+// Delim writes a delimiter. Delimiters are inserted automatically between
+// members and elements that lack one, so Delim is only kept for code written
+// against the append_delim methods.
 func (enc *Encoder) Delim() {
-	enc.AppendByte(delim)
+	enc.delimited()
+	enc.appendByte(delim)
 }
 
+// EncodeKey writes the escaped value enclosed in quotation marks, i.e. a
+// string value following ObjectKey. A delimiter is inserted first if the
+// previous element has not been followed by one.
 func (enc *Encoder) EncodeKey(value []byte) {
-	enc.AppendByte(quoteMark)
-	enc.AppendBytes(value)
-	enc.AppendByte(quoteMark)
+	enc.beginValue()
+	enc.encodeKey(value)
+	enc.endValue()
+}
+
+// encodeKey writes the escaped value enclosed in quotation marks, leaving the
+// nesting state alone.
+func (enc *Encoder) encodeKey(value []byte) {
+	enc.appendByte(quoteMark)
+	writeEscaped(enc, value)
+	enc.appendByte(quoteMark)
 }
 
 func (enc *Encoder) WriteUint32Key(key []byte, value uint32, append_delim bool) {
@@ -458,26 +621,48 @@ func (enc *Encoder) WriteEncodedFloat64Key(key []byte, value float64, append_del
 }
 
 func (enc *Encoder) WriteUint32Timestamp(key []byte, value uint32, append_delim bool) {
+	enc.ObjectKey(key)
+	enc.writeUint32Timestamp(value)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteTimeKey writes the key and the time as an ISO8601 value, formatted
+// like WriteUint32Timestamp.
+func (enc *Encoder) WriteTimeKey(key []byte, value time.Time, append_delim bool) {
+	enc.ObjectKey(key)
+	enc.writeTime(value)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// writeUint32Timestamp writes the unix timestamp as a quoted ISO8601 value.
+func (enc *Encoder) writeUint32Timestamp(value uint32) {
+	enc.writeTime(time.Unix(int64(value), 0))
+}
+
+// writeTime writes the time as a quoted ISO8601 value, in UTC if
+// UTCTimestamps is set and in local time otherwise.
+func (enc *Encoder) writeTime(t time.Time) {
 	b := bufPool.Get().(*bytes.Buffer)
 	b.Reset()
 	defer bufPool.Put(b)
 
-	t := time.Unix(int64(value), 0)
-
 	if enc.c.UTCTimestamps {
 		b.Write(t.UTC().AppendFormat(b.Bytes(), ISO8601u))
 	} else {
-		b.Write(t.AppendFormat(b.Bytes(), ISO8601))
+		b.Write(t.Local().AppendFormat(b.Bytes(), ISO8601))
 	}
-	enc.ObjectKey(key)
-	enc.AppendByte(quoteMark)
-	enc.AppendBytes(b.Bytes())
-	enc.AppendByte(quoteMark)
+	enc.beginValue()
+	enc.appendByte(quoteMark)
+	enc.appendBytes(b.Bytes())
+	enc.appendByte(quoteMark)
+	enc.endValue()
 	b.Reset()
-
-	if append_delim {
-		enc.Delim()
-	}
 }
 
 func (enc *Encoder) RoundFloat(value float64) float64 {
@@ -486,43 +671,63 @@ func (enc *Encoder) RoundFloat(value float64) float64 {
 }
 
 func (enc *Encoder) writeUint64Key(key []byte, value uint64, delim, encode bool) {
+	enc.ObjectKey(key)
+	enc.writeUint64(value, encode)
+
+	if delim {
+		enc.Delim()
+	}
+}
+
+// writeUint64 writes the value, quoted if encode is set.
+func (enc *Encoder) writeUint64(value uint64, encode bool) {
 	b := bufPool.Get().(*bytes.Buffer)
 	b.Reset()
 	defer bufPool.Put(b)
 
 	b.Write(strconv.AppendUint(b.Bytes(), value, 10))
-	enc.ObjectKey(key)
+	enc.beginValue()
 	if encode {
-		enc.EncodeKey(b.Bytes())
+		enc.encodeKey(b.Bytes())
 	} else {
-		enc.AppendBytes(b.Bytes())
+		enc.appendBytes(b.Bytes())
 	}
+	enc.endValue()
 	b.Reset()
+}
+
+func (enc *Encoder) writeFloat64Key(key []byte, value float64, delim, encode bool) {
+	enc.ObjectKey(key)
+	enc.writeFloat64(value, encode)
 
 	if delim {
 		enc.Delim()
 	}
 }
 
-func (enc *Encoder) writeFloat64Key(key []byte, value float64, delim, encode bool) {
+// writeFloat64 writes the value, rounded if configured, and quoted if encode
+// is set.
+func (enc *Encoder) writeFloat64(value float64, encode bool) {
 	b := bufPool.Get().(*bytes.Buffer)
 	b.Reset()
 	defer bufPool.Put(b)
 
-	if enc.c.Round {
-		value = enc.RoundFloat(value)
-	}
-
-	b.Write(strconv.AppendFloat(b.Bytes(), value, 'f', -1, 64))
-	enc.ObjectKey(key)
+	b.Write(enc.appendFloat64(b.Bytes(), value))
+	enc.beginValue()
 	if encode {
-		enc.EncodeKey(b.Bytes())
+		enc.encodeKey(b.Bytes())
 	} else {
-		enc.AppendBytes(b.Bytes())
+		enc.appendBytes(b.Bytes())
 	}
+	enc.endValue()
 	b.Reset()
+}
 
-	if delim {
-		enc.Delim()
+// appendFloat64 appends the value, rounded if configured, to dst.
+func (enc *Encoder) appendFloat64(dst []byte, value float64) []byte {
+	if enc.c.Round {
+		value = enc.RoundFloat(value)
 	}
+
+	return strconv.AppendFloat(dst, value, 'f', -1, 64)
 }
@@ -1,9 +1,13 @@
 package encoder
 
 import (
+	"bufio"
 	"bytes"
+	"encoding/json"
 	"io"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -28,6 +32,75 @@ func TestEncoderGetEncoder(t *testing.T) {
 	})
 }
 
+// closeBuffer is a bytes.Buffer which records calls to Close.
+type closeBuffer struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *closeBuffer) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestEncoderGetWriterEncoder(t *testing.T) {
+	t.Run("writer", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		enc := GetWriterEncoder(buf)
+		defer enc.Release()
+
+		enc.WriteUint64Key([]byte("foo"), 1, false)
+		assert.NoError(t, enc.Close())
+		assert.Equal(t, `"foo":1`, buf.String())
+	})
+
+	t.Run("closer", func(t *testing.T) {
+		buf := new(closeBuffer)
+		enc := GetWriterEncoder(buf)
+		defer enc.Release()
+
+		enc.WriteUint64Key([]byte("foo"), 1, false)
+		assert.NoError(t, enc.Close())
+		assert.Equal(t, `"foo":1`, buf.String())
+		assert.False(t, buf.closed)
+	})
+
+	t.Run("write closer", func(t *testing.T) {
+		buf := new(closeBuffer)
+		enc := GetWriteCloserEncoder(buf)
+		defer enc.Release()
+
+		enc.WriteUint64Key([]byte("foo"), 1, false)
+		assert.NoError(t, enc.Close())
+		assert.Equal(t, `"foo":1`, buf.String())
+		assert.True(t, buf.closed)
+	})
+
+	t.Run("http flusher", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		enc := GetWriterEncoder(rec)
+		defer enc.Release()
+
+		enc.WriteUint64Key([]byte("foo"), 1, false)
+		assert.NoError(t, enc.Write())
+		assert.True(t, rec.Flushed)
+		assert.Equal(t, `"foo":1`, rec.Body.String())
+	})
+
+	t.Run("buffered writer", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		bw := bufio.NewWriter(buf)
+		enc := GetWriterEncoder(bw)
+		defer enc.Release()
+
+		enc.WriteUint64Key([]byte("foo"), 1, false)
+		assert.NoError(t, enc.Write())
+		assert.Equal(t, 0, buf.Len())
+		assert.NoError(t, enc.Close())
+		assert.Equal(t, `"foo":1`, buf.String())
+	})
+}
+
 func BenchmarkEncoderGetEncoder(b *testing.B) {
 	_, w := io.Pipe()
 	b.ResetTimer()
@@ -43,9 +116,11 @@ func TestEncoderRelease(t *testing.T) {
 	t.Run("resets encoder", func(t *testing.T) {
 		_, w := io.Pipe()
 		enc := GetEncoder(w)
+		enc.setErr(io.ErrClosedPipe)
 		enc.Release()
 		assert.Nil(t, enc.w)
 		assert.Nil(t, enc.b)
+		assert.Nil(t, enc.err)
 	})
 
 	t.Run("remaining writes", func(t *testing.T) {
@@ -94,6 +169,16 @@ func TestEncoderClose(t *testing.T) {
 		})
 	})
 
+	t.Run("returns error", func(t *testing.T) {
+		r, w := io.Pipe()
+		enc := GetEncoder(w)
+		defer enc.Release()
+		enc.AppendBytes(make([]byte, 10))
+		r.Close()
+
+		assert.ErrorIs(t, enc.Close(), io.ErrClosedPipe)
+	})
+
 	t.Run("writes remaining", func(t *testing.T) {
 		r, w := io.Pipe()
 		enc := GetEncoder(w)
@@ -124,16 +209,23 @@ func TestEncoderWrite(t *testing.T) {
 		assert.Equal(t, int64(0), enc.f)
 	})
 
-	t.Run("panics on error", func(t *testing.T) {
+	t.Run("records error", func(t *testing.T) {
 		r, w := io.Pipe()
 		enc := GetEncoder(w)
 		defer enc.Release()
 		enc.AppendByte(byte(1))
 		r.Close()
 
-		assert.Panics(t, func() {
-			enc.Write()
+		assert.NotPanics(t, func() {
+			assert.ErrorIs(t, enc.Write(), io.ErrClosedPipe)
 		})
+		assert.ErrorIs(t, enc.Err(), io.ErrClosedPipe)
+
+		// further writes are no-ops.
+		enc.AppendByte(byte(1))
+		enc.WriteUint64Key([]byte("foo"), 1, false)
+		assert.Equal(t, 0, enc.Len())
+		assert.ErrorIs(t, enc.Close(), io.ErrClosedPipe)
 	})
 
 	t.Run("canceled", func(t *testing.T) {
@@ -204,6 +296,14 @@ func TestEncoderEncodeKey(t *testing.T) {
 	assert.Equal(t, `"/foo/bar"`, enc.b.String())
 }
 
+func TestEncoderEncodeKeyEscapes(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	enc.EncodeKey([]byte("a \"b\"\tc\\"))
+	assert.Equal(t, `"a \"b\"\tc\\"`, enc.b.String())
+	assert.True(t, json.Valid(enc.Bytes()))
+}
+
 func BenchmarkEncoderEncodeKey(b *testing.B) {
 	enc := GetEncoder(nil)
 	defer enc.Release()
@@ -236,6 +336,18 @@ func BenchmarkEncoderObjectKey(b *testing.B) {
 	}
 }
 
+func BenchmarkEncoderKey(b *testing.B) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	value := NewKey("/foo/bar")
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		enc.b.Reset()
+		enc.Key(value)
+	}
+}
+
 func TestEncoderWriteUint32Key(t *testing.T) {
 	enc := GetEncoder(nil)
 	defer enc.Release()
@@ -314,6 +426,20 @@ func BenchmarkEncoderWriteUint64Key(b *testing.B) {
 	}
 }
 
+func BenchmarkEncoderKeyUint64(b *testing.B) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	value := uint64(473829)
+	key := NewKey("/foo/bar")
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		enc.b.Reset()
+		enc.Key(key)
+		enc.Uint64(value)
+	}
+}
+
 func TestEncoderWriteFloat64Key(t *testing.T) {
 	enc := GetEncoder(nil)
 	defer enc.Release()
@@ -382,129 +508,6 @@ func TestEncoderObjectEnd(t *testing.T) {
 	assert.Equal(t, "}", enc.b.String())
 }
 
-func TestEncoderPrettyPrint(t *testing.T) {
-	t.Run("empty buffer", func(t *testing.T) {
-		enc := GetEncoder(nil)
-		defer enc.Release()
-		enc.PrettyPrint()
-		assert.Equal(t, 0, enc.Len())
-	})
-
-	t.Run("empty array", func(t *testing.T) {
-		enc := GetEncoder(nil)
-		defer enc.Release()
-
-		enc.ObjectStart()
-		enc.ObjectKey([]byte("keys"))
-		enc.ArrayStart()
-		enc.ArrayEnd()
-		enc.ObjectEnd()
-		enc.PrettyPrint()
-		assert.Equal(t, "{\n    \"keys\": [\n        \n    ]\n}", string(enc.Bytes()))
-	})
-
-	t.Run("delim", func(t *testing.T) {
-		enc := GetEncoder(nil)
-		defer enc.Release()
-
-		enc.ObjectStart()
-		enc.ObjectKey([]byte("foo"))
-		enc.ArrayStart()
-		enc.ArrayEnd()
-		enc.Delim()
-		enc.ObjectKey([]byte("bar"))
-		enc.ArrayStart()
-		enc.ArrayEnd()
-		enc.ObjectEnd()
-		enc.PrettyPrint()
-		assert.Equal(t, "{\n    \"foo\": [\n        \n    ],\n    \"bar\": [\n        \n    ]\n}", string(enc.Bytes()))
-	})
-
-	t.Run("nested", func(t *testing.T) {
-		enc := GetEncoder(nil)
-		defer enc.Release()
-
-		enc.ObjectStart()
-		enc.ObjectKey([]byte("foo"))
-		enc.ArrayStart()
-		enc.ObjectStart()
-		enc.WriteUint32Key([]byte("bar"), 0, true)
-		enc.WriteUint32Key([]byte("baz"), 0, false)
-		enc.ObjectEnd()
-		enc.ArrayEnd()
-		enc.ObjectEnd()
-
-		enc.PrettyPrint()
-		assert.Equal(t, "{\n    \"foo\": [\n        {\n            \"bar\": 0,\n            \"baz\": 0\n        }\n    ]\n}", string(enc.Bytes()))
-	})
-
-	t.Run("tab mode", func(t *testing.T) {
-		enc := GetEncoder(nil)
-		enc.SetConfig(EncoderConfig{Indent: TAB_MODE})
-		defer enc.Release()
-
-		enc.ObjectStart()
-		enc.ObjectKey([]byte("keys"))
-		enc.ArrayStart()
-		enc.ArrayEnd()
-		enc.ObjectEnd()
-		enc.PrettyPrint()
-		assert.Equal(t, "{\n\t\"keys\": [\n\t\t\n\t]\n}", string(enc.Bytes()))
-	})
-
-	t.Run("split buffer", func(t *testing.T) {
-		enc := GetEncoder(nil)
-		defer enc.Release()
-
-		enc.AppendBytes([]byte(`"timestamp`))
-		enc.PrettyPrint()
-		assert.Equal(t, `"timestamp`, string(enc.Bytes()))
-		enc.b.Reset()
-
-		enc.AppendBytes([]byte(`":"1970-01-01T01:00:00"`))
-		enc.PrettyPrint()
-		assert.Equal(t, `": "1970-01-01T01:00:00"`, string(enc.Bytes()))
-	})
-
-	t.Run("split string", func(t *testing.T) {
-		enc := GetEncoder(nil)
-		defer enc.Release()
-
-		enc.AppendBytes([]byte(`"foo{}[],:`))
-		enc.PrettyPrint()
-		assert.Equal(t, `"foo{}[],:`, string(enc.Bytes()))
-		enc.b.Reset()
-
-		enc.AppendBytes([]byte(`bar{}[],:"`))
-		enc.PrettyPrint()
-		assert.Equal(t, `bar{}[],:"`, string(enc.Bytes()))
-	})
-
-	t.Run("string", func(t *testing.T) {
-		enc := GetEncoder(nil)
-		defer enc.Release()
-		enc.EncodeKey([]byte("a{}/string[]:,"))
-		enc.PrettyPrint()
-		assert.Equal(t, `"a{}/string[]:,"`, string(enc.Bytes()))
-	})
-}
-
-func BenchmarkEncoderPrettyPrint(b *testing.B) {
-	enc := GetEncoder(nil)
-	key := []byte("foo")
-	defer enc.Release()
-
-	for i := 0; i < b.N; i++ {
-		enc.ObjectStart()
-		enc.ObjectKey(key)
-		enc.ArrayStart()
-		enc.ArrayEnd()
-		enc.ObjectEnd()
-		enc.PrettyPrint()
-		enc.b.Reset()
-	}
-}
-
 func TestEncoderWriteUint32Timestamp(t *testing.T) {
 	enc := GetEncoder(nil)
 	defer enc.Release()
@@ -549,6 +552,16 @@ func TestEncoderWriteUint32Timestamp(t *testing.T) {
 	})
 }
 
+func TestEncoderWriteTimeKey(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	enc.c.UTCTimestamps = true
+	ts := time.Date(2021, 2, 5, 13, 5, 32, 0, time.FixedZone("", 3600))
+
+	enc.WriteTimeKey([]byte("timestamp"), ts, true)
+	assert.Equal(t, `"timestamp":"2021-02-05T12:05:32+00",`, string(enc.Bytes()))
+}
+
 func BenchmarkWriteUint32Timestamp(b *testing.B) {
 	enc := GetEncoder(nil)
 	defer enc.Release()
@@ -587,6 +600,31 @@ func TestEncoderEscape(t *testing.T) {
 		good := " "
 		assert.Equal(t, []byte(good), enc.Escape([]byte(bad)))
 	})
+
+	t.Run("preserve newlines", func(t *testing.T) {
+		enc.c.PreserveNewlines = true
+		defer func() { enc.c.PreserveNewlines = false }()
+		bad := "a\nb"
+		good := `a\nb`
+		assert.Equal(t, []byte(good), enc.Escape([]byte(bad)))
+	})
+
+	t.Run("quotes", func(t *testing.T) {
+		bad := `say "hi"`
+		good := `say \"hi\"`
+		assert.Equal(t, []byte(good), enc.Escape([]byte(bad)))
+	})
+
+	t.Run("control characters", func(t *testing.T) {
+		bad := "\t\r\b\f\x00\x1f"
+		good := `\t\r\b\f\u0000\u001f`
+		assert.Equal(t, []byte(good), enc.Escape([]byte(bad)))
+	})
+
+	t.Run("unicode", func(t *testing.T) {
+		value := "héllo, 世界"
+		assert.Equal(t, []byte(value), enc.Escape([]byte(value)))
+	})
 }
 
 func TestEncoderRoundFloat(t *testing.T) {
@@ -0,0 +1,57 @@
+package encoder
+
+const hex = "0123456789abcdef"
+
+// appendEscaped appends value to dst, escaped as per RFC 8259: quotation
+// marks, backslashes and control characters are escaped. Newlines are
+// escaped as \n if newlines is set, otherwise they are replaced with a space.
+//
+// value is accepted as a []byte or a string, so neither has to be converted.
+func appendEscaped[T []byte | string](dst []byte, value T, newlines bool) []byte {
+	start := 0
+
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c >= space && c != quoteMark && c != backslash {
+			continue
+		}
+
+		dst = append(dst, value[start:i]...)
+		start = i + 1
+
+		switch c {
+		case quoteMark, backslash:
+			dst = append(dst, backslash, c)
+		case newLine:
+			if newlines {
+				dst = append(dst, backslash, 'n')
+			} else {
+				dst = append(dst, space)
+			}
+		case '\r':
+			dst = append(dst, backslash, 'r')
+		case tab:
+			dst = append(dst, backslash, 't')
+		case '\b':
+			dst = append(dst, backslash, 'b')
+		case '\f':
+			dst = append(dst, backslash, 'f')
+		default:
+			dst = append(dst, backslash, 'u', '0', '0', hex[c>>4], hex[c&0xf])
+		}
+	}
+
+	return append(dst, value[start:]...)
+}
+
+// writeEscaped writes the escaped value to the encoder buffer.
+func writeEscaped[T []byte | string](enc *Encoder, value T) {
+	if !enc.ok() {
+		return
+	}
+
+	// reserve room for the value, so appending does not allocate.
+	enc.b.Grow(len(value))
+	enc.b.Write(appendEscaped(enc.b.AvailableBuffer(), value, enc.c.PreserveNewlines))
+	enc.flush()
+}
@@ -0,0 +1,48 @@
+package encoder
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendEscaped(t *testing.T) {
+	t.Run("matches encoding/json", func(t *testing.T) {
+		for c := 0; c < 128; c++ {
+			// encoding/json escapes HTML characters as well.
+			if c == '<' || c == '>' || c == '&' {
+				continue
+			}
+
+			value := string([]byte{'a', byte(c), 'b'})
+			want, _ := json.Marshal(value)
+			got := append([]byte{quoteMark}, appendEscaped(nil, value, true)...)
+			got = append(got, quoteMark)
+
+			var decoded string
+			assert.NoError(t, json.Unmarshal(got, &decoded), "byte %#x", c)
+			assert.Equal(t, value, decoded, "byte %#x", c)
+			assert.Equal(t, string(want), string(got), "byte %#x", c)
+		}
+	})
+
+	t.Run("string and bytes", func(t *testing.T) {
+		value := "a\"b\nc"
+		assert.Equal(t, appendEscaped(nil, value, false), appendEscaped(nil, []byte(value), false))
+		assert.Equal(t, `a\"b c`, string(appendEscaped(nil, value, false)))
+		assert.Equal(t, `a\"b\nc`, string(appendEscaped(nil, value, true)))
+	})
+}
+
+func BenchmarkEncoderEscapedEncodeKey(b *testing.B) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	value := []byte("/foo/\"bar\"\n")
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		enc.b.Reset()
+		enc.EncodeKey(value)
+	}
+}
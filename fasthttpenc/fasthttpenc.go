@@ -0,0 +1,103 @@
+// Package fasthttpenc streams Encoder output as a fasthttp response body.
+//
+// It owns the io.Pipe, goroutine and Release() lifecycle every handler would
+// otherwise have to repeat:
+//
+//	func ApiHandler(ctx *fasthttp.RequestCtx) {
+//		fasthttpenc.StreamJSON(ctx, FunkyEncoder)
+//	}
+//
+//	func FunkyEncoder(enc *encoder.Encoder) {
+//		enc.ObjectStart()
+//		enc.WriteUint32Timestamp(...)
+//		enc.WriteUint32Key(...)
+//		enc.ObjectEnd()
+//	}
+package fasthttpenc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	encoder "github.com/simook/jsonencoder"
+	"github.com/valyala/fasthttp"
+)
+
+// Config controls how StreamJSONConfig runs the encoder.
+type Config struct {
+	// Timeout cancels the encoder and closes the body stream once the given
+	// duration has passed. Zero disables the timeout.
+	Timeout time.Duration
+	// Encoder, if not nil, is set as the config of the encoder. It replaces
+	// the default config, so it needs to be complete.
+	Encoder *encoder.EncoderConfig
+	// Done, if not nil, is called once the encoder has been released.
+	Done func(Stats)
+}
+
+// Stats describes a finished stream.
+type Stats struct {
+	Writes int64 // number of writes to the body stream.
+	Bytes  int64 // number of bytes written to the body stream.
+	Size   int   // encoder buffer size in bytes.
+	Err    error // first error encountered, a recovered panic or context.DeadlineExceeded.
+}
+
+// StreamJSON sets the JSON written by fn as the body stream of ctx using the
+// default config. See StreamJSONConfig.
+func StreamJSON(ctx *fasthttp.RequestCtx, fn func(*encoder.Encoder)) {
+	StreamJSONConfig(ctx, Config{}, fn)
+}
+
+// StreamJSONConfig sets the JSON written by fn as the body stream of ctx. The
+// response is sent with chunked transfer encoding.
+//
+// fn is run in its own goroutine. The encoder is closed once fn returns and is
+// always released back to the pool, even if fn panics; the panic is recovered
+// and the body stream is closed with an error.
+func StreamJSONConfig(ctx *fasthttp.RequestCtx, cfg Config, fn func(*encoder.Encoder)) {
+	r, w := io.Pipe()
+	enc := encoder.GetEncoder(w)
+	if cfg.Encoder != nil {
+		enc.SetConfig(*cfg.Encoder)
+	}
+
+	ctx.SetContentType("application/json")
+	// calling SetBodyStream with a negative size enables chunked transfers.
+	ctx.SetBodyStream(r, -1)
+
+	c, cancel := context.WithCancel(context.Background())
+	if cfg.Timeout > 0 {
+		c, cancel = context.WithTimeout(context.Background(), cfg.Timeout)
+	}
+	enc.WithContext(c)
+	// terminate the reader once the timeout has passed.
+	context.AfterFunc(c, func() { w.Close() })
+
+	go func() {
+		var err error
+
+		defer func() {
+			if p := recover(); p != nil {
+				err = fmt.Errorf("fasthttpenc: recovered panic: %v", p)
+				w.CloseWithError(err) // terminate the reader.
+			}
+			// stop the timeout, the encoder is done.
+			cancel()
+
+			writes, n, size := enc.Release()
+			if cfg.Done != nil {
+				cfg.Done(Stats{Writes: writes, Bytes: n, Size: size, Err: err})
+			}
+		}()
+
+		fn(enc)
+		err = enc.Close()
+		if err == nil {
+			// the output has been cut short by the timeout.
+			err = c.Err()
+		}
+	}()
+}
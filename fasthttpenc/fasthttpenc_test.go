@@ -0,0 +1,82 @@
+package fasthttpenc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	encoder "github.com/simook/jsonencoder"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestStreamJSON(t *testing.T) {
+	var ctx fasthttp.RequestCtx
+
+	StreamJSON(&ctx, func(enc *encoder.Encoder) {
+		enc.ObjectStart()
+		enc.WriteUint64Key([]byte("foo"), 1, false)
+		enc.ObjectEnd()
+	})
+
+	assert.Equal(t, "application/json", string(ctx.Response.Header.ContentType()))
+	assert.Equal(t, `{"foo":1}`, string(ctx.Response.Body()))
+}
+
+func TestStreamJSONConfig(t *testing.T) {
+	t.Run("stats", func(t *testing.T) {
+		var ctx fasthttp.RequestCtx
+		done := make(chan Stats, 1)
+
+		StreamJSONConfig(&ctx, Config{Done: func(s Stats) { done <- s }}, func(enc *encoder.Encoder) {
+			enc.WriteUint64Key([]byte("foo"), 1, false)
+		})
+
+		assert.Equal(t, `"foo":1`, string(ctx.Response.Body()))
+		s := <-done
+		assert.NoError(t, s.Err)
+		assert.Equal(t, int64(1), s.Writes)
+		assert.Equal(t, int64(7), s.Bytes)
+	})
+
+	t.Run("encoder config", func(t *testing.T) {
+		var ctx fasthttp.RequestCtx
+		cfg := encoder.EncoderConfig{Pretty: true, Indent: encoder.TAB_MODE}
+
+		StreamJSONConfig(&ctx, Config{Encoder: &cfg}, func(enc *encoder.Encoder) {
+			enc.ObjectStart()
+			enc.WriteUint64Key([]byte("foo"), 1, false)
+			enc.ObjectEnd()
+		})
+
+		assert.Equal(t, "{\n\t\"foo\": 1\n}", string(ctx.Response.Body()))
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		var ctx fasthttp.RequestCtx
+		done := make(chan Stats, 1)
+
+		StreamJSONConfig(&ctx, Config{Timeout: time.Millisecond, Done: func(s Stats) { done <- s }}, func(enc *encoder.Encoder) {
+			<-enc.Done()
+			enc.WriteUint64Key([]byte("foo"), 1, false)
+		})
+
+		assert.Empty(t, ctx.Response.Body())
+		s := <-done
+		assert.Equal(t, int64(0), s.Bytes)
+		assert.ErrorIs(t, s.Err, context.DeadlineExceeded)
+	})
+
+	t.Run("recovers", func(t *testing.T) {
+		var ctx fasthttp.RequestCtx
+		done := make(chan Stats, 1)
+
+		// the timeout is stopped once the panic has been recovered.
+		StreamJSONConfig(&ctx, Config{Timeout: time.Hour, Done: func(s Stats) { done <- s }}, func(enc *encoder.Encoder) {
+			panic("boom")
+		})
+
+		ctx.Response.Body()
+		assert.ErrorContains(t, (<-done).Err, "boom")
+	})
+}
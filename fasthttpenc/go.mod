@@ -0,0 +1,20 @@
+module github.com/simook/jsonencoder/fasthttpenc
+
+go 1.22.3
+
+require (
+	github.com/simook/jsonencoder v0.1.0
+	github.com/stretchr/testify v1.9.0
+	github.com/valyala/fasthttp v1.55.0
+)
+
+require (
+	github.com/andybalholm/brotli v1.1.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/klauspost/compress v1.17.9 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/simook/jsonencoder => ../
@@ -0,0 +1,69 @@
+package encoder
+
+import (
+	"slices"
+)
+
+// WriteArray writes the key and the values as an array, calling fn to write
+// each element with the element writers (Uint64, Str, ObjectStart, ...).
+// Delimiters are managed by the Encoder. If key is nil, the array is written
+// as an element of the enclosing array.
+//
+//	WriteArray(enc, points, values, func(enc *Encoder, p Point) {
+//		enc.ObjectStart()
+//		enc.Float64Field(x, p.X)
+//		enc.Float64Field(y, p.Y)
+//		enc.ObjectEnd()
+//	})
+func WriteArray[T any](enc *Encoder, key []byte, values []T, fn func(*Encoder, T)) {
+	if key != nil {
+		enc.ObjectKey(key)
+	}
+
+	enc.ArrayStart()
+	for _, v := range values {
+		if !enc.ok() {
+			break
+		}
+		fn(enc, v)
+	}
+	enc.ArrayEnd()
+}
+
+// WriteMap writes the key and the values as an object, calling fn to write
+// each value with the element writers. Delimiters are managed by the Encoder.
+// If key is nil, the object is written as an element of the enclosing array.
+//
+// If sorted is set, the members are written in key order. Sorting allocates a
+// slice of the keys; otherwise the order is random, as with any map iteration.
+func WriteMap[K ~string, V any](enc *Encoder, key []byte, values map[K]V, fn func(*Encoder, V), sorted bool) {
+	if key != nil {
+		enc.ObjectKey(key)
+	}
+
+	enc.ObjectStart()
+	if sorted {
+		keys := make([]K, 0, len(values))
+		for k := range values {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+
+		for _, k := range keys {
+			if !enc.ok() {
+				break
+			}
+			writeObjectKey(enc, string(k))
+			fn(enc, values[k])
+		}
+	} else {
+		for k, v := range values {
+			if !enc.ok() {
+				break
+			}
+			writeObjectKey(enc, string(k))
+			fn(enc, v)
+		}
+	}
+	enc.ObjectEnd()
+}
@@ -0,0 +1,93 @@
+package encoder
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type point struct {
+	X, Y float64
+}
+
+func writePoint(enc *Encoder, p point) {
+	enc.ObjectStart()
+	enc.Float64Field([]byte("x"), p.X)
+	enc.Float64Field([]byte("y"), p.Y)
+	enc.ObjectEnd()
+}
+
+func TestWriteArray(t *testing.T) {
+	t.Run("key", func(t *testing.T) {
+		enc := GetEncoder(nil)
+		defer enc.Release()
+
+		enc.ObjectStart()
+		WriteArray(enc, []byte("points"), []point{{1, 2}, {3, 4}}, writePoint)
+		WriteArray(enc, []byte("empty"), []point{}, writePoint)
+		enc.ObjectEnd()
+		assert.Equal(t, `{"points":[{"x":1,"y":2},{"x":3,"y":4}],"empty":[]}`, enc.b.String())
+	})
+
+	t.Run("element", func(t *testing.T) {
+		enc := GetEncoder(nil)
+		defer enc.Release()
+
+		rows := [][]uint64{{1, 2}, {3}}
+		WriteArray(enc, nil, rows, func(enc *Encoder, row []uint64) {
+			WriteArray(enc, nil, row, (*Encoder).Uint64)
+		})
+		assert.Equal(t, `[[1,2],[3]]`, enc.b.String())
+	})
+}
+
+type label string
+
+func TestWriteMap(t *testing.T) {
+	values := map[label]uint64{"b": 2, "a": 1, `"c"`: 3}
+
+	t.Run("sorted", func(t *testing.T) {
+		enc := GetEncoder(nil)
+		defer enc.Release()
+
+		enc.ObjectStart()
+		WriteMap(enc, []byte("counts"), values, (*Encoder).Uint64, true)
+		enc.ObjectEnd()
+		assert.Equal(t, `{"counts":{"\"c\"":3,"a":1,"b":2}}`, enc.b.String())
+	})
+
+	t.Run("unsorted", func(t *testing.T) {
+		enc := GetEncoder(nil)
+		defer enc.Release()
+
+		WriteMap(enc, nil, values, (*Encoder).Uint64, false)
+
+		var decoded map[label]uint64
+		assert.NoError(t, json.Unmarshal(enc.Bytes(), &decoded))
+		assert.Equal(t, values, decoded)
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		enc := GetEncoder(nil)
+		defer enc.Release()
+
+		nested := map[string][]point{"a": {{1, 2}}, "b": nil}
+		WriteMap(enc, nil, nested, func(enc *Encoder, points []point) {
+			WriteArray(enc, nil, points, writePoint)
+		}, true)
+		assert.Equal(t, `{"a":[{"x":1,"y":2}],"b":[]}`, enc.b.String())
+	})
+}
+
+func BenchmarkWriteArray(b *testing.B) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	key := []byte("/foo/bar")
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		enc.b.Reset()
+		WriteArray(enc, key, uint64Values, (*Encoder).Uint64)
+	}
+}
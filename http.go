@@ -0,0 +1,58 @@
+package encoder
+
+import (
+	"net/http"
+)
+
+// ServeJSON streams the JSON written by fn to w.
+//
+// The response is sent with chunked transfer encoding; the underlying
+// http.Flusher is flushed every time the encoder buffer reaches MAXBUFSIZE.
+// Once the request context is canceled, i.e. the client went away, all
+// further writes are dropped.
+//
+// If fn (or the encoder, up to and including Close) fails before anything
+// has been written to w, the buffered output is discarded and a 500 Internal
+// Server Error is sent instead. Otherwise the response is cut short. In both
+// cases the error is returned so the caller can log it.
+//
+//	func ApiHandler(w http.ResponseWriter, r *http.Request) {
+//		ServeJSON(w, r, func(enc *Encoder) error {
+//			enc.ObjectStart()
+//			enc.WriteUint32Key(...)
+//			enc.ObjectEnd()
+//			return nil
+//		})
+//	}
+func ServeJSON(w http.ResponseWriter, r *http.Request, fn func(*Encoder) error) error {
+	enc := GetWriterEncoder(w)
+	defer enc.Release()
+	enc.WithContext(r.Context())
+
+	h := w.Header()
+	h.Set("Content-Type", "application/json")
+	h.Del("Content-Length")
+
+	err := fn(enc)
+	if err == nil {
+		err = enc.Err()
+	}
+	if err == nil {
+		// Close may fail before anything has been written, i.e. in Strict
+		// mode with an unclosed object.
+		err = enc.Close()
+	}
+
+	if err != nil {
+		// stop any writers.
+		enc.cancel()
+
+		// nothing has been sent yet, respond with an error instead.
+		if enc.f == 0 {
+			enc.b.Reset()
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return err
+	}
+	return r.Context().Err()
+}
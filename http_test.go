@@ -0,0 +1,101 @@
+package encoder
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServeJSON(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+
+		err := ServeJSON(w, r, func(enc *Encoder) error {
+			enc.ObjectStart()
+			enc.WriteUint64Key([]byte("foo"), 1, false)
+			enc.ObjectEnd()
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		assert.Equal(t, `{"foo":1}`, w.Body.String())
+		assert.True(t, w.Flushed)
+	})
+
+	t.Run("flushes", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+
+		err := ServeJSON(w, r, func(enc *Encoder) error {
+			enc.AppendBytes(make([]byte, MAXBUFSIZE))
+			assert.True(t, w.Flushed)
+			assert.Equal(t, MAXBUFSIZE, w.Body.Len())
+			return nil
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("error before write", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+		fail := errors.New("fail")
+
+		err := ServeJSON(w, r, func(enc *Encoder) error {
+			enc.ObjectStart()
+			return fail
+		})
+		assert.ErrorIs(t, err, fail)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.NotContains(t, w.Body.String(), "{")
+	})
+
+	t.Run("close error before write", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+
+		err := ServeJSON(w, r, func(enc *Encoder) error {
+			enc.SetConfig(EncoderConfig{Strict: true})
+			enc.ObjectStart()
+			return nil
+		})
+		assert.EqualError(t, err, "encoder: unclosed object or array at offset 1 ($)")
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.NotContains(t, w.Body.String(), "{")
+	})
+
+	t.Run("error after write", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+		fail := errors.New("fail")
+
+		err := ServeJSON(w, r, func(enc *Encoder) error {
+			enc.AppendBytes(make([]byte, MAXBUFSIZE))
+			enc.ObjectStart()
+			return fail
+		})
+		assert.ErrorIs(t, err, fail)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, MAXBUFSIZE, w.Body.Len())
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		r := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+		w := httptest.NewRecorder()
+
+		err := ServeJSON(w, r, func(enc *Encoder) error {
+			cancel()
+			enc.ObjectStart()
+			enc.ObjectEnd()
+			return nil
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 0, w.Body.Len())
+	})
+}
@@ -0,0 +1,114 @@
+package encoder
+
+import (
+	"bytes"
+	"io"
+)
+
+// Indent reads the JSON from src and writes it to dst, indented as the
+// Encoder does with EncoderConfig.Pretty and the rest of config, i.e.
+// IndentString, MaxWidth and Color. The JSON is read and written in chunks of
+// MAXBUFSIZE, so streams of any size are indented in constant memory.
+//
+// src may hold a stream of whitespace separated values, i.e. NDJSON, which
+// are written one after the other separated by a newline.
+//
+// Invalid JSON is reported as a *SyntaxError holding the byte offset in src.
+// The JSON preceding the error may already be written to dst.
+func Indent(dst io.Writer, src io.Reader, config EncoderConfig) error {
+	config.Pretty = true
+	return transform(dst, src, &config)
+}
+
+// Compact reads the JSON from src and writes it to dst with the insignificant
+// whitespace removed. A stream of values is written one value per line.
+// Errors are reported as by Indent.
+func Compact(dst io.Writer, src io.Reader) error {
+	return transform(dst, src, &EncoderConfig{})
+}
+
+// Valid reports whether src holds valid JSON: one value, or a stream of
+// whitespace separated values.
+func Valid(src io.Reader) bool {
+	return transform(nil, src, nil) == nil
+}
+
+// transform streams the JSON read from src to an Encoder writing to dst,
+// configured by config. Without an Encoder the JSON is only validated.
+func transform(dst io.Writer, src io.Reader, config *EncoderConfig) error {
+	s := scanPool.Get().(*scanner)
+	s.reset()
+	defer scanPool.Put(s)
+
+	// a buffer to read src into.
+	rb := bufPool.Get().(*bytes.Buffer)
+	rb.Reset()
+	if rb.Cap() < MAXBUFSIZE {
+		rb.Grow(MAXBUFSIZE - rb.Cap())
+	}
+	defer bufPool.Put(rb)
+	in := rb.AvailableBuffer()
+	in = in[:cap(in)]
+
+	var enc *Encoder
+	if config != nil {
+		enc = GetWriterEncoder(dst)
+		defer enc.Release()
+		enc.SetConfig(*config)
+	}
+
+	// the last byte written, to tell whether a value ended with a newline.
+	var last byte
+
+	for {
+		n, err := src.Read(in)
+
+		for _, c := range in[:n] {
+			switch s.scan(c) {
+			case scanSpace:
+				continue
+			case scanError:
+				if enc != nil {
+					enc.Close()
+				}
+				return s.err
+			case scanNext:
+				// separate the values, unless the pretty print already has.
+				if enc != nil && !(enc.c.Pretty && enc.c.TrailingNewline && (last == rBrace || last == rBracket)) {
+					enc.b.WriteByte(newLine)
+				}
+			}
+
+			if enc != nil {
+				enc.b.WriteByte(c)
+				last = c
+			}
+		}
+
+		if enc != nil {
+			enc.flush()
+			if !enc.ok() {
+				return enc.Err()
+			}
+		}
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := s.eof(); err != nil {
+		if enc != nil {
+			enc.Close()
+		}
+		return err
+	}
+
+	if enc != nil {
+		return enc.Close()
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package encoder
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndent(t *testing.T) {
+	src := " {\n\t\"a\" : [ 1, \"b \\\" c\" , {} ],\r\n \"d\": { \"e\" : null } } "
+
+	t.Run("json.Indent", func(t *testing.T) {
+		var want bytes.Buffer
+		json.Indent(&want, []byte(src), "> ", "  ")
+
+		var got bytes.Buffer
+		err := Indent(&got, strings.NewReader(src), EncoderConfig{IndentString: "  ", Prefix: "> "})
+		assert.NoError(t, err)
+		assert.Equal(t, strings.TrimSpace(want.String()), got.String())
+	})
+
+	t.Run("one byte reads", func(t *testing.T) {
+		var want, got bytes.Buffer
+		assert.NoError(t, Indent(&want, strings.NewReader(src), EncoderConfig{MaxWidth: 40}))
+		assert.NoError(t, Indent(&got, iotest.OneByteReader(strings.NewReader(src)), EncoderConfig{MaxWidth: 40}))
+		assert.Equal(t, want.String(), got.String())
+		assert.Equal(t, "{\n    \"a\": [\n        1,\n        \"b \\\" c\",\n        {}\n    ],\n    \"d\": {\"e\": null}\n}", got.String())
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		var got bytes.Buffer
+		err := Indent(&got, strings.NewReader("{\"a\":1}\n[]\n2 3"), EncoderConfig{IndentString: " "})
+		assert.NoError(t, err)
+		assert.Equal(t, "{\n \"a\": 1\n}\n[]\n2\n3", got.String())
+
+		got.Reset()
+		err = Indent(&got, strings.NewReader("{\"a\":1}\n[]\n2 3"), EncoderConfig{IndentString: " ", TrailingNewline: true})
+		assert.NoError(t, err)
+		assert.Equal(t, "{\n \"a\": 1\n}\n[]\n2\n3\n", got.String())
+	})
+
+	t.Run("large", func(t *testing.T) {
+		var src bytes.Buffer
+		src.WriteByte('[')
+		for i := 0; i < 2000; i++ {
+			if i > 0 {
+				src.WriteByte(',')
+			}
+			src.WriteString(`{"key": "value", "n": [1, 2.5e3, true]}`)
+		}
+		src.WriteByte(']')
+
+		var want bytes.Buffer
+		json.Indent(&want, src.Bytes(), "", "\t")
+
+		var got bytes.Buffer
+		assert.NoError(t, Indent(&got, &src, EncoderConfig{IndentString: "\t"}))
+		assert.Equal(t, want.String(), got.String())
+	})
+
+	t.Run("color", func(t *testing.T) {
+		var got bytes.Buffer
+		err := Indent(&got, strings.NewReader(`{"a": 1}`), EncoderConfig{Color: COLOR_ALWAYS, Palette: &markers})
+		assert.NoError(t, err)
+		assert.Equal(t, "<p>{"+colorReset+"\n    <k>\"a\""+colorReset+"<p>:"+colorReset+" <n>1"+colorReset+"\n<p>}"+colorReset, got.String())
+	})
+
+	t.Run("does not close dst", func(t *testing.T) {
+		r, w := io.Pipe()
+		go func() {
+			assert.NoError(t, Indent(w, strings.NewReader(`[1]`), EncoderConfig{}))
+			w.Write([]byte("\n"))
+			w.Close()
+		}()
+		b, err := io.ReadAll(r)
+		assert.NoError(t, err)
+		assert.Equal(t, "[\n    1\n]\n", string(b))
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		err := Indent(io.Discard, strings.NewReader(`{"a": 1,}`), EncoderConfig{})
+		var se *SyntaxError
+		assert.True(t, errors.As(err, &se))
+		assert.Equal(t, "invalid character '}' looking for beginning of object key string", se.Msg)
+		assert.Equal(t, int64(8), se.Offset)
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		err := Indent(io.Discard, iotest.ErrReader(io.ErrUnexpectedEOF), EncoderConfig{})
+		assert.Equal(t, io.ErrUnexpectedEOF, err)
+	})
+}
+
+func TestCompact(t *testing.T) {
+	var got bytes.Buffer
+	err := Compact(&got, strings.NewReader(" { \"a b\" : [ 1 , true ] }\n\n{\"c\":\n\"\\\\\"}\n-1.5e+3 "))
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"a b\":[1,true]}\n{\"c\":\"\\\\\"}\n-1.5e+3", got.String())
+
+	err = Compact(io.Discard, strings.NewReader(`[1, 2`))
+	assert.EqualError(t, err, "encoder: unexpected end of JSON input at offset 5")
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		src   string
+		valid bool
+	}{
+		{`{"a":[1,-0.5e-3,"\u00e9\n",true,false,null,{}]}`, true},
+		{"1 2\n[3]\n", true},
+		{`1`, true},
+		{``, false},
+		{`   `, false},
+		{`{"a"}`, false},
+		{`{"a":1,}`, false},
+		{`[1,]`, false},
+		{`[1 2]`, false},
+		{`01`, false},
+		{`[]{}`, false},
+		{`1.`, false},
+		{`1e`, false},
+		{`-`, false},
+		{`"\x"`, false},
+		{`"\u12g4"`, false},
+		{"\"\t\"", false},
+		{`tru`, false},
+		{`nul1`, false},
+		{`{1:2}`, false},
+		{`[}`, false},
+		{strings.Repeat("[", maxScanDepth+1), false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.valid, Valid(strings.NewReader(tt.src)), tt.src)
+	}
+}
+
+func FuzzIndent(f *testing.F) {
+	f.Add([]byte(` {"a" : [1, "b\\\"", {"c" :null}],"d\"":true} `))
+	f.Add([]byte("[\"\\u0022\", -0.5E+2]\n{}\n"))
+	f.Add([]byte(`1 2`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var got bytes.Buffer
+		err := Indent(&got, iotest.HalfReader(bytes.NewReader(data)), EncoderConfig{})
+		assert.Equal(t, err == nil, Valid(bytes.NewReader(data)))
+
+		if json.Valid(data) {
+			var want bytes.Buffer
+			json.Indent(&want, bytes.TrimSpace(data), "", "    ")
+			assert.NoError(t, err)
+			assert.Equal(t, want.String(), got.String())
+			return
+		}
+
+		if err != nil {
+			var se *SyntaxError
+			assert.True(t, errors.As(err, &se))
+			return
+		}
+
+		// a stream of values, each of which must be valid.
+		dec := json.NewDecoder(bytes.NewReader(data))
+		for {
+			var v json.RawMessage
+			err := dec.Decode(&v)
+			if err == io.EOF {
+				break
+			}
+			assert.NoError(t, err)
+			if err != nil {
+				return
+			}
+		}
+	})
+}
+
+func BenchmarkIndent(b *testing.B) {
+	src := []byte(`{"timestamp": "2024-01-01T00:00:00Z", "values": [1, 2.5, null, true], "name": "foo \"bar\""}`)
+	r := bytes.NewReader(src)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r.Reset(src)
+		Indent(io.Discard, r, EncoderConfig{})
+	}
+}
+
+func BenchmarkCompact(b *testing.B) {
+	src := []byte(`{"timestamp": "2024-01-01T00:00:00Z", "values": [1, 2.5, null, true], "name": "foo \"bar\""}`)
+	r := bytes.NewReader(src)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r.Reset(src)
+		Compact(io.Discard, r)
+	}
+}
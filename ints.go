@@ -0,0 +1,108 @@
+package encoder
+
+import (
+	"bytes"
+	"strconv"
+)
+
+func (enc *Encoder) WriteInt8Key(key []byte, value int8, append_delim bool) {
+	enc.writeInt64Key(key, int64(value), append_delim, false)
+}
+
+func (enc *Encoder) WriteEncodedInt8Key(key []byte, value int8, append_delim bool) {
+	enc.writeInt64Key(key, int64(value), append_delim, true)
+}
+
+func (enc *Encoder) WriteInt16Key(key []byte, value int16, append_delim bool) {
+	enc.writeInt64Key(key, int64(value), append_delim, false)
+}
+
+func (enc *Encoder) WriteEncodedInt16Key(key []byte, value int16, append_delim bool) {
+	enc.writeInt64Key(key, int64(value), append_delim, true)
+}
+
+func (enc *Encoder) WriteInt32Key(key []byte, value int32, append_delim bool) {
+	enc.writeInt64Key(key, int64(value), append_delim, false)
+}
+
+func (enc *Encoder) WriteEncodedInt32Key(key []byte, value int32, append_delim bool) {
+	enc.writeInt64Key(key, int64(value), append_delim, true)
+}
+
+func (enc *Encoder) WriteInt64Key(key []byte, value int64, append_delim bool) {
+	enc.writeInt64Key(key, value, append_delim, false)
+}
+
+func (enc *Encoder) WriteEncodedInt64Key(key []byte, value int64, append_delim bool) {
+	enc.writeInt64Key(key, value, append_delim, true)
+}
+
+// Int8Field writes the key and value. Delimiters are managed by the Encoder.
+func (enc *Encoder) Int8Field(key []byte, value int8) {
+	enc.writeInt64Key(key, int64(value), false, false)
+}
+
+// EncodedInt8Field writes the key and quoted value. Delimiters are managed
+// by the Encoder.
+func (enc *Encoder) EncodedInt8Field(key []byte, value int8) {
+	enc.writeInt64Key(key, int64(value), false, true)
+}
+
+// Int16Field writes the key and value. Delimiters are managed by the Encoder.
+func (enc *Encoder) Int16Field(key []byte, value int16) {
+	enc.writeInt64Key(key, int64(value), false, false)
+}
+
+// EncodedInt16Field writes the key and quoted value. Delimiters are managed
+// by the Encoder.
+func (enc *Encoder) EncodedInt16Field(key []byte, value int16) {
+	enc.writeInt64Key(key, int64(value), false, true)
+}
+
+// Int32Field writes the key and value. Delimiters are managed by the Encoder.
+func (enc *Encoder) Int32Field(key []byte, value int32) {
+	enc.writeInt64Key(key, int64(value), false, false)
+}
+
+// EncodedInt32Field writes the key and quoted value. Delimiters are managed
+// by the Encoder.
+func (enc *Encoder) EncodedInt32Field(key []byte, value int32) {
+	enc.writeInt64Key(key, int64(value), false, true)
+}
+
+// Int64Field writes the key and value. Delimiters are managed by the Encoder.
+func (enc *Encoder) Int64Field(key []byte, value int64) {
+	enc.writeInt64Key(key, value, false, false)
+}
+
+// EncodedInt64Field writes the key and quoted value. Delimiters are managed
+// by the Encoder.
+func (enc *Encoder) EncodedInt64Field(key []byte, value int64) {
+	enc.writeInt64Key(key, value, false, true)
+}
+
+func (enc *Encoder) writeInt64Key(key []byte, value int64, delim, encode bool) {
+	enc.ObjectKey(key)
+	enc.writeInt64(value, encode)
+
+	if delim {
+		enc.Delim()
+	}
+}
+
+// writeInt64 writes the value, quoted if encode is set.
+func (enc *Encoder) writeInt64(value int64, encode bool) {
+	b := bufPool.Get().(*bytes.Buffer)
+	b.Reset()
+	defer bufPool.Put(b)
+
+	b.Write(strconv.AppendInt(b.Bytes(), value, 10))
+	enc.beginValue()
+	if encode {
+		enc.encodeKey(b.Bytes())
+	} else {
+		enc.appendBytes(b.Bytes())
+	}
+	enc.endValue()
+	b.Reset()
+}
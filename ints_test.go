@@ -0,0 +1,114 @@
+package encoder
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncoderWriteInt8Key(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	key := []byte("/foo/bar")
+	enc.WriteInt8Key(key, math.MinInt8, true)
+	assert.Equal(t, `"/foo/bar":-128,`, enc.b.String())
+
+	enc.b.Reset()
+	enc.WriteEncodedInt8Key(key, math.MaxInt8, false)
+	assert.Equal(t, `"/foo/bar":"127"`, enc.b.String())
+}
+
+func TestEncoderWriteInt16Key(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	key := []byte("/foo/bar")
+	enc.WriteInt16Key(key, -4738, true)
+	assert.Equal(t, `"/foo/bar":-4738,`, enc.b.String())
+
+	enc.b.Reset()
+	enc.WriteEncodedInt16Key(key, -4738, false)
+	assert.Equal(t, `"/foo/bar":"-4738"`, enc.b.String())
+}
+
+func TestEncoderWriteInt32Key(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	value := int32(-473829)
+	key := []byte("/foo/bar")
+	enc.WriteInt32Key(key, value, true)
+	assert.Equal(t, `"/foo/bar":-473829,`, enc.b.String())
+
+	enc.b.Reset()
+	enc.WriteInt32Key(key, value, false)
+	assert.Equal(t, `"/foo/bar":-473829`, enc.b.String())
+}
+
+func TestEncoderWriteEncodedInt32Key(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	value := int32(-473829)
+	key := []byte("/foo/bar")
+	enc.WriteEncodedInt32Key(key, value, true)
+	assert.Equal(t, `"/foo/bar":"-473829",`, enc.b.String())
+
+	enc.b.Reset()
+	enc.WriteEncodedInt32Key(key, value, false)
+	assert.Equal(t, `"/foo/bar":"-473829"`, enc.b.String())
+}
+
+func TestEncoderWriteInt64Key(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	value := int64(math.MinInt64)
+	key := []byte("/foo/bar")
+	enc.WriteInt64Key(key, value, true)
+	assert.Equal(t, `"/foo/bar":-9223372036854775808,`, enc.b.String())
+
+	enc.b.Reset()
+	enc.WriteInt64Key(key, 0, false)
+	assert.Equal(t, `"/foo/bar":0`, enc.b.String())
+}
+
+func TestEncoderWriteEncodedInt64Key(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	value := int64(-1 << 60)
+	key := []byte("/foo/bar")
+	enc.WriteEncodedInt64Key(key, value, true)
+	assert.Equal(t, `"/foo/bar":"-1152921504606846976",`, enc.b.String())
+
+	enc.b.Reset()
+	enc.WriteEncodedInt64Key(key, value, false)
+	assert.Equal(t, `"/foo/bar":"-1152921504606846976"`, enc.b.String())
+}
+
+func TestEncoderIntFields(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+
+	enc.ObjectStart()
+	enc.Int8Field([]byte("a"), -1)
+	enc.Int16Field([]byte("b"), -2)
+	enc.Int32Field([]byte("c"), -3)
+	enc.Int64Field([]byte("d"), -4)
+	enc.EncodedInt8Field([]byte("e"), -5)
+	enc.EncodedInt16Field([]byte("f"), -6)
+	enc.EncodedInt32Field([]byte("g"), -7)
+	enc.EncodedInt64Field([]byte("h"), -8)
+	enc.ObjectEnd()
+	assert.Equal(t, `{"a":-1,"b":-2,"c":-3,"d":-4,"e":"-5","f":"-6","g":"-7","h":"-8"}`, enc.b.String())
+}
+
+func BenchmarkEncoderWriteInt64Key(b *testing.B) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	value := int64(-473829)
+	key := []byte("/foo/bar")
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		enc.b.Reset()
+		enc.WriteInt64Key(key, value, true)
+	}
+}
@@ -0,0 +1,462 @@
+package encoder
+
+import (
+	"database/sql"
+	"encoding"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Key is an object key quoted and escaped once, up front, for keys written in
+// hot loops. It is written with Encoder.Key, followed by the value, or with
+// the QuotedKey variant of a Write...Key method:
+//
+//	var timestamp = NewKey("timestamp")
+//
+//	enc.Key(timestamp)
+//	enc.Timestamp(ts)
+//
+// A Key is written with a single copy instead of being quoted and escaped on
+// every call. The zero Key is the empty key.
+//
+// A newline in a Key is written like ObjectKey writes it, as per
+// EncoderConfig.PreserveNewlines, so such keys are escaped on every call.
+type Key struct {
+	quoted []byte // the quoted and escaped key followed by a colon.
+	name   string // the key, if it holds a newline.
+}
+
+// NewKey returns the Key holding the quoted and escaped key followed by a
+// colon, i.e. "timestamp":
+func NewKey(key string) Key {
+	k := make([]byte, 0, len(key)+3)
+	k = append(k, quoteMark)
+	k = appendEscaped(k, key, true)
+
+	var name string
+	if strings.IndexByte(key, newLine) >= 0 {
+		name = key
+	}
+	return Key{quoted: append(k, quoteMark, colon), name: name}
+}
+
+// String returns the quoted key, i.e. "timestamp":, with any newline escaped
+// as \n.
+func (k Key) String() string {
+	if k.quoted == nil {
+		return `"":`
+	}
+	return string(k.quoted)
+}
+
+// Key writes the Key, like ObjectKey. A delimiter is inserted first if the
+// previous member has not been followed by one.
+func (enc *Encoder) Key(key Key) {
+	enc.writeKey(key)
+}
+
+// writeKey writes the Key, inserting a delimiter first if the previous member
+// has not been followed by one.
+func (enc *Encoder) writeKey(key Key) {
+	if key.quoted == nil || key.name != "" && !enc.c.PreserveNewlines {
+		writeObjectKey(enc, key.name)
+		return
+	}
+
+	// the key path of Strict mode holds the escaped, unquoted name.
+	beginKey(enc, key.quoted[1:len(key.quoted)-2])
+	if !enc.ok() {
+		return
+	}
+
+	enc.b.Write(key.quoted)
+	enc.flush()
+}
+
+// The QuotedKey methods are the Write...Key methods taking a Key instead of
+// the key bytes, so the key is written with a single copy:
+//
+//	var count = NewKey("count")
+//
+//	enc.WriteUint64QuotedKey(count, n, false)
+
+// WriteUint32QuotedKey is WriteUint32Key with a Key.
+func (enc *Encoder) WriteUint32QuotedKey(key Key, value uint32, append_delim bool) {
+	enc.writeKey(key)
+	enc.writeUint64(uint64(value), false)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteEncodedUint32QuotedKey is WriteEncodedUint32Key with a Key.
+func (enc *Encoder) WriteEncodedUint32QuotedKey(key Key, value uint32, append_delim bool) {
+	enc.writeKey(key)
+	enc.writeUint64(uint64(value), true)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteUint64QuotedKey is WriteUint64Key with a Key.
+func (enc *Encoder) WriteUint64QuotedKey(key Key, value uint64, append_delim bool) {
+	enc.writeKey(key)
+	enc.writeUint64(value, false)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteEncodedUint64QuotedKey is WriteEncodedUint64Key with a Key.
+func (enc *Encoder) WriteEncodedUint64QuotedKey(key Key, value uint64, append_delim bool) {
+	enc.writeKey(key)
+	enc.writeUint64(value, true)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteInt8QuotedKey is WriteInt8Key with a Key.
+func (enc *Encoder) WriteInt8QuotedKey(key Key, value int8, append_delim bool) {
+	enc.writeKey(key)
+	enc.writeInt64(int64(value), false)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteEncodedInt8QuotedKey is WriteEncodedInt8Key with a Key.
+func (enc *Encoder) WriteEncodedInt8QuotedKey(key Key, value int8, append_delim bool) {
+	enc.writeKey(key)
+	enc.writeInt64(int64(value), true)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteInt16QuotedKey is WriteInt16Key with a Key.
+func (enc *Encoder) WriteInt16QuotedKey(key Key, value int16, append_delim bool) {
+	enc.writeKey(key)
+	enc.writeInt64(int64(value), false)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteEncodedInt16QuotedKey is WriteEncodedInt16Key with a Key.
+func (enc *Encoder) WriteEncodedInt16QuotedKey(key Key, value int16, append_delim bool) {
+	enc.writeKey(key)
+	enc.writeInt64(int64(value), true)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteInt32QuotedKey is WriteInt32Key with a Key.
+func (enc *Encoder) WriteInt32QuotedKey(key Key, value int32, append_delim bool) {
+	enc.writeKey(key)
+	enc.writeInt64(int64(value), false)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteEncodedInt32QuotedKey is WriteEncodedInt32Key with a Key.
+func (enc *Encoder) WriteEncodedInt32QuotedKey(key Key, value int32, append_delim bool) {
+	enc.writeKey(key)
+	enc.writeInt64(int64(value), true)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteInt64QuotedKey is WriteInt64Key with a Key.
+func (enc *Encoder) WriteInt64QuotedKey(key Key, value int64, append_delim bool) {
+	enc.writeKey(key)
+	enc.writeInt64(value, false)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteEncodedInt64QuotedKey is WriteEncodedInt64Key with a Key.
+func (enc *Encoder) WriteEncodedInt64QuotedKey(key Key, value int64, append_delim bool) {
+	enc.writeKey(key)
+	enc.writeInt64(value, true)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteFloat64QuotedKey is WriteFloat64Key with a Key.
+func (enc *Encoder) WriteFloat64QuotedKey(key Key, value float64, append_delim bool) {
+	enc.writeKey(key)
+	enc.writeFloat64(value, false)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteEncodedFloat64QuotedKey is WriteEncodedFloat64Key with a Key.
+func (enc *Encoder) WriteEncodedFloat64QuotedKey(key Key, value float64, append_delim bool) {
+	enc.writeKey(key)
+	enc.writeFloat64(value, true)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteUint32TimestampQuotedKey is WriteUint32Timestamp with a Key.
+func (enc *Encoder) WriteUint32TimestampQuotedKey(key Key, value uint32, append_delim bool) {
+	enc.writeKey(key)
+	enc.writeUint32Timestamp(value)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteTimeQuotedKey is WriteTimeKey with a Key.
+func (enc *Encoder) WriteTimeQuotedKey(key Key, value time.Time, append_delim bool) {
+	enc.writeKey(key)
+	enc.writeTime(value)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteStrBytesQuotedKey is WriteStrBytesKey with a Key.
+func (enc *Encoder) WriteStrBytesQuotedKey(key Key, value []byte, append_delim bool) {
+	enc.writeKey(key)
+	writeString(enc, value)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteStrQuotedKey is WriteStrKey with a Key.
+func (enc *Encoder) WriteStrQuotedKey(key Key, value string, append_delim bool) {
+	enc.writeKey(key)
+	writeString(enc, value)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteBoolQuotedKey is WriteBoolKey with a Key.
+func (enc *Encoder) WriteBoolQuotedKey(key Key, value bool, append_delim bool) {
+	enc.writeKey(key)
+	enc.Bool(value)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteNullQuotedKey is WriteNullKey with a Key.
+func (enc *Encoder) WriteNullQuotedKey(key Key, append_delim bool) {
+	enc.writeKey(key)
+	enc.Null()
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteUint64ArrayQuotedKey is WriteUint64ArrayKey with a Key.
+func (enc *Encoder) WriteUint64ArrayQuotedKey(key Key, values []uint64, append_delim bool) {
+	enc.writeKey(key)
+	enc.writeUint64Array(values)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteInt64ArrayQuotedKey is WriteInt64ArrayKey with a Key.
+func (enc *Encoder) WriteInt64ArrayQuotedKey(key Key, values []int64, append_delim bool) {
+	enc.writeKey(key)
+	enc.writeInt64Array(values)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteFloat64ArrayQuotedKey is WriteFloat64ArrayKey with a Key.
+func (enc *Encoder) WriteFloat64ArrayQuotedKey(key Key, values []float64, append_delim bool) {
+	enc.writeKey(key)
+	enc.writeFloat64Array(values)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteStringArrayQuotedKey is WriteStringArrayKey with a Key.
+func (enc *Encoder) WriteStringArrayQuotedKey(key Key, values []string, append_delim bool) {
+	enc.writeKey(key)
+	enc.writeStringArray(values)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteInt64PtrQuotedKey is WriteInt64PtrKey with a Key.
+func (enc *Encoder) WriteInt64PtrQuotedKey(key Key, value *int64, append_delim bool) {
+	enc.writeKey(key)
+	enc.Int64Ptr(value)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteUint64PtrQuotedKey is WriteUint64PtrKey with a Key.
+func (enc *Encoder) WriteUint64PtrQuotedKey(key Key, value *uint64, append_delim bool) {
+	enc.writeKey(key)
+	enc.Uint64Ptr(value)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteFloat64PtrQuotedKey is WriteFloat64PtrKey with a Key.
+func (enc *Encoder) WriteFloat64PtrQuotedKey(key Key, value *float64, append_delim bool) {
+	enc.writeKey(key)
+	enc.Float64Ptr(value)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteStringPtrQuotedKey is WriteStringPtrKey with a Key.
+func (enc *Encoder) WriteStringPtrQuotedKey(key Key, value *string, append_delim bool) {
+	enc.writeKey(key)
+	enc.StringPtr(value)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteBoolPtrQuotedKey is WriteBoolPtrKey with a Key.
+func (enc *Encoder) WriteBoolPtrQuotedKey(key Key, value *bool, append_delim bool) {
+	enc.writeKey(key)
+	enc.BoolPtr(value)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteNullInt64QuotedKey is WriteNullInt64Key with a Key.
+func (enc *Encoder) WriteNullInt64QuotedKey(key Key, value sql.NullInt64, append_delim bool) {
+	enc.writeKey(key)
+	enc.NullInt64(value)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteNullFloat64QuotedKey is WriteNullFloat64Key with a Key.
+func (enc *Encoder) WriteNullFloat64QuotedKey(key Key, value sql.NullFloat64, append_delim bool) {
+	enc.writeKey(key)
+	enc.NullFloat64(value)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteNullStringQuotedKey is WriteNullStringKey with a Key.
+func (enc *Encoder) WriteNullStringQuotedKey(key Key, value sql.NullString, append_delim bool) {
+	enc.writeKey(key)
+	enc.NullString(value)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteNullTimeQuotedKey is WriteNullTimeKey with a Key.
+func (enc *Encoder) WriteNullTimeQuotedKey(key Key, value sql.NullTime, append_delim bool) {
+	enc.writeKey(key)
+	enc.NullTime(value)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteValueQuotedKey is WriteValueKey with a Key.
+func (enc *Encoder) WriteValueQuotedKey(key Key, v EncoderMarshaler, append_delim bool) {
+	enc.writeKey(key)
+	enc.Value(v)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteMarshalerQuotedKey is WriteMarshalerKey with a Key.
+func (enc *Encoder) WriteMarshalerQuotedKey(key Key, v json.Marshaler, append_delim bool) {
+	enc.writeKey(key)
+	enc.Marshaler(v)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteTextMarshalerQuotedKey is WriteTextMarshalerKey with a Key.
+func (enc *Encoder) WriteTextMarshalerQuotedKey(key Key, v encoding.TextMarshaler, append_delim bool) {
+	enc.writeKey(key)
+	enc.TextMarshaler(v)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteRawQuotedKey is WriteRawKey with a Key.
+func (enc *Encoder) WriteRawQuotedKey(key Key, raw []byte, append_delim bool) {
+	enc.writeKey(key)
+	enc.WriteRaw(raw)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteStructQuotedKey is WriteStructKey with a Key.
+func (enc *Encoder) WriteStructQuotedKey(key Key, v any, append_delim bool) {
+	enc.writeKey(key)
+	enc.encode(v)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
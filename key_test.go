@@ -0,0 +1,162 @@
+package encoder
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewKey(t *testing.T) {
+	assert.Equal(t, `"timestamp":`, NewKey("timestamp").String())
+	assert.Equal(t, `"a \"b\"\n":`, NewKey("a \"b\"\n").String())
+	assert.Equal(t, `"":`, NewKey("").String())
+	assert.Equal(t, `"":`, Key{}.String())
+}
+
+func TestEncoderKey(t *testing.T) {
+	ts := uint32(1700000000)
+	n := int64(-1)
+
+	// each Write...Key method and the Key followed by the matching element
+	// writer.
+	writers := map[string]struct {
+		key   func(enc *Encoder, key []byte)
+		value func(enc *Encoder)
+	}{
+		"ObjectKey":            {func(enc *Encoder, key []byte) { enc.ObjectKey(key) }, func(enc *Encoder) {}},
+		"WriteUint64Key":       {func(enc *Encoder, key []byte) { enc.WriteUint64Key(key, 1, false) }, func(enc *Encoder) { enc.Uint64(1) }},
+		"WriteFloat64Key":      {func(enc *Encoder, key []byte) { enc.WriteFloat64Key(key, 1.5, false) }, func(enc *Encoder) { enc.Float64(1.5) }},
+		"WriteUint32Timestamp": {func(enc *Encoder, key []byte) { enc.WriteUint32Timestamp(key, ts, false) }, func(enc *Encoder) { enc.Timestamp(ts) }},
+		"WriteTimeKey":         {func(enc *Encoder, key []byte) { enc.WriteTimeKey(key, time.Unix(0, 0), false) }, func(enc *Encoder) { enc.Time(time.Unix(0, 0)) }},
+		"WriteInt64Key":        {func(enc *Encoder, key []byte) { enc.WriteInt64Key(key, -1, false) }, func(enc *Encoder) { enc.Int64(-1) }},
+		"WriteStrKey":          {func(enc *Encoder, key []byte) { enc.WriteStrKey(key, "v", false) }, func(enc *Encoder) { enc.Str("v") }},
+		"WriteBoolKey":         {func(enc *Encoder, key []byte) { enc.WriteBoolKey(key, true, false) }, func(enc *Encoder) { enc.Bool(true) }},
+		"WriteNullKey":         {func(enc *Encoder, key []byte) { enc.WriteNullKey(key, false) }, func(enc *Encoder) { enc.Null() }},
+		"WriteRawKey":          {func(enc *Encoder, key []byte) { enc.WriteRawKey(key, []byte(`{}`), false) }, func(enc *Encoder) { enc.WriteRaw([]byte(`{}`)) }},
+		"Uint64ArrayField":     {func(enc *Encoder, key []byte) { enc.Uint64ArrayField(key, []uint64{1, 2}) }, func(enc *Encoder) { enc.Uint64Array([]uint64{1, 2}) }},
+		"WriteInt64PtrKey":     {func(enc *Encoder, key []byte) { enc.WriteInt64PtrKey(key, &n, false) }, func(enc *Encoder) { enc.Int64Ptr(&n) }},
+		"WriteNullStringKey":   {func(enc *Encoder, key []byte) { enc.WriteNullStringKey(key, sql.NullString{}, false) }, func(enc *Encoder) { enc.NullString(sql.NullString{}) }},
+		"StructField":          {func(enc *Encoder, key []byte) { enc.StructField(key, point{1, 2}) }, func(enc *Encoder) { enc.Encode(point{1, 2}) }},
+	}
+
+	for name, write := range writers {
+		for _, config := range []EncoderConfig{{}, {PreserveNewlines: true}} {
+			for _, key := range []string{"a\tb", "a\nb"} {
+				t.Run(name, func(t *testing.T) {
+					want := GetEncoder(nil)
+					want.SetConfig(config)
+					defer want.Release()
+					want.ObjectStart()
+					write.key(want, []byte(key))
+
+					enc := GetEncoder(nil)
+					enc.SetConfig(config)
+					defer enc.Release()
+					enc.ObjectStart()
+					enc.Key(NewKey(key))
+					write.value(enc)
+					assert.Equal(t, want.b.String(), enc.b.String())
+				})
+			}
+		}
+	}
+}
+
+func TestEncoderQuotedKey(t *testing.T) {
+	typ := reflect.TypeOf(&Encoder{})
+	n := 0
+
+	// each ...QuotedKey method and the Write...Key method it mirrors, called
+	// with zero values.
+	for i := 0; i < typ.NumMethod(); i++ {
+		m := typ.Method(i)
+		name, ok := strings.CutSuffix(m.Name, "QuotedKey")
+		if !ok {
+			continue
+		}
+		mirror, ok := typ.MethodByName(name + "Key")
+		if !ok {
+			mirror, ok = typ.MethodByName(name)
+		}
+		if !assert.True(t, ok, m.Name) {
+			continue
+		}
+		n++
+
+		for _, config := range []EncoderConfig{{}, {PreserveNewlines: true}} {
+			write := func(m reflect.Method, key any) string {
+				enc := GetEncoder(nil)
+				enc.SetConfig(config)
+				defer enc.Release()
+				enc.ObjectStart()
+
+				args := []reflect.Value{reflect.ValueOf(enc), reflect.ValueOf(key)}
+				for j := 2; j < m.Type.NumIn()-1; j++ {
+					args = append(args, reflect.Zero(m.Type.In(j)))
+				}
+				m.Func.Call(append(args, reflect.ValueOf(true)))
+				return enc.b.String()
+			}
+			got := write(m, NewKey("a\nb"))
+			assert.Equal(t, write(mirror, []byte("a\nb")), got, m.Name)
+			// Release recovers panics, which leave the output empty.
+			assert.Contains(t, got, `"a`, m.Name)
+		}
+	}
+	assert.Equal(t, 38, n)
+}
+
+func TestEncoderKeyNewline(t *testing.T) {
+	key := NewKey("a\nb")
+
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	enc.ObjectStart()
+	enc.Key(key)
+	enc.Uint64(1)
+	enc.ObjectEnd()
+	assert.Equal(t, `{"a b":1}`, enc.b.String())
+
+	enc.b.Reset()
+	enc.SetConfig(EncoderConfig{PreserveNewlines: true})
+	enc.ObjectStart()
+	enc.Key(key)
+	enc.Uint64(1)
+	enc.ObjectEnd()
+	assert.Equal(t, `{"a\nb":1}`, enc.b.String())
+}
+
+func TestEncoderKeyDelimiters(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+
+	foo, bar := NewKey("foo"), NewKey("bar")
+	enc.ObjectStart()
+	enc.Key(foo)
+	enc.Uint64(1)
+	enc.Key(bar)
+	enc.Uint64(2)
+	enc.Key(Key{})
+	enc.Uint64(3)
+	enc.ObjectEnd()
+	assert.Equal(t, `{"foo":1,"bar":2,"":3}`, enc.b.String())
+}
+
+func TestEncoderKeyStrict(t *testing.T) {
+	enc := GetEncoder(nil)
+	enc.SetConfig(EncoderConfig{Strict: true})
+	defer enc.Release()
+
+	enc.ObjectStart()
+	enc.Key(NewKey("foo"))
+	enc.Key(NewKey("bar"))
+
+	var serr *SyntaxError
+	assert.True(t, errors.As(enc.Err(), &serr))
+	assert.Equal(t, "$.foo", serr.Path)
+}
@@ -0,0 +1,70 @@
+package encoder
+
+var (
+	trueLiteral  = []byte("true")
+	falseLiteral = []byte("false")
+	nullLiteral  = []byte("null")
+)
+
+// WriteBoolKey writes the key and true or false.
+func (enc *Encoder) WriteBoolKey(key []byte, value bool, append_delim bool) {
+	enc.ObjectKey(key)
+	enc.Bool(value)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteNullKey writes the key and null.
+func (enc *Encoder) WriteNullKey(key []byte, append_delim bool) {
+	enc.ObjectKey(key)
+	enc.Null()
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// BoolField writes the key and true or false. Delimiters are managed by the
+// Encoder.
+func (enc *Encoder) BoolField(key []byte, value bool) {
+	enc.WriteBoolKey(key, value, false)
+}
+
+// NullField writes the key and null. Delimiters are managed by the Encoder.
+func (enc *Encoder) NullField(key []byte) {
+	enc.WriteNullKey(key, false)
+}
+
+// Bool writes true or false as an array element. Delimiters are managed by
+// the Encoder.
+func (enc *Encoder) Bool(value bool) {
+	enc.beginValue()
+	if value {
+		enc.appendBytes(trueLiteral)
+	} else {
+		enc.appendBytes(falseLiteral)
+	}
+	enc.endValue()
+}
+
+// EncodedBool writes "true" or "false" as an array element. Delimiters are
+// managed by the Encoder.
+func (enc *Encoder) EncodedBool(value bool) {
+	enc.beginValue()
+	if value {
+		enc.encodeKey(trueLiteral)
+	} else {
+		enc.encodeKey(falseLiteral)
+	}
+	enc.endValue()
+}
+
+// Null writes null as an array element. Delimiters are managed by the
+// Encoder.
+func (enc *Encoder) Null() {
+	enc.beginValue()
+	enc.appendBytes(nullLiteral)
+	enc.endValue()
+}
@@ -0,0 +1,48 @@
+package encoder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncoderWriteBoolKey(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	key := []byte("/foo/bar")
+	enc.WriteBoolKey(key, true, true)
+	assert.Equal(t, `"/foo/bar":true,`, enc.b.String())
+
+	enc.b.Reset()
+	enc.WriteBoolKey(key, false, false)
+	assert.Equal(t, `"/foo/bar":false`, enc.b.String())
+}
+
+func TestEncoderWriteNullKey(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	key := []byte("/foo/bar")
+	enc.WriteNullKey(key, true)
+	assert.Equal(t, `"/foo/bar":null,`, enc.b.String())
+
+	enc.b.Reset()
+	enc.WriteNullKey(key, false)
+	assert.Equal(t, `"/foo/bar":null`, enc.b.String())
+}
+
+func TestEncoderLiterals(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+
+	enc.ObjectStart()
+	enc.BoolField([]byte("a"), true)
+	enc.NullField([]byte("b"))
+	enc.ObjectKey([]byte("c"))
+	enc.ArrayStart()
+	enc.Bool(false)
+	enc.Null()
+	enc.Bool(true)
+	enc.ArrayEnd()
+	enc.ObjectEnd()
+	assert.Equal(t, `{"a":true,"b":null,"c":[false,null,true]}`, enc.b.String())
+}
@@ -0,0 +1,160 @@
+package encoder
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// EncoderMarshaler is implemented by types which encode themselves with the
+// Encoder, i.e. by writing an object:
+//
+//	func (p *Point) EncodeJSON(enc *Encoder) {
+//		enc.ObjectStart()
+//		enc.Float64Field(x, p.X)
+//		enc.Float64Field(y, p.Y)
+//		enc.ObjectEnd()
+//	}
+//
+// EncodeJSON must write exactly one value. Delimiters are managed by the
+// Encoder.
+type EncoderMarshaler interface {
+	EncodeJSON(enc *Encoder)
+}
+
+// WriteValueKey writes the key and the value encoded by v, or null if v is
+// nil.
+func (enc *Encoder) WriteValueKey(key []byte, v EncoderMarshaler, append_delim bool) {
+	enc.ObjectKey(key)
+	enc.Value(v)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// ValueField writes the key and the value encoded by v, or null if v is nil.
+// Delimiters are managed by the Encoder.
+func (enc *Encoder) ValueField(key []byte, v EncoderMarshaler) {
+	enc.WriteValueKey(key, v, false)
+}
+
+// Value writes the value encoded by v, or null if v is nil, as an array
+// element.
+func (enc *Encoder) Value(v EncoderMarshaler) {
+	if isNil(v) {
+		enc.Null()
+		return
+	}
+
+	v.EncodeJSON(enc)
+}
+
+// WriteMarshalerKey writes the key and the JSON returned by v.MarshalJSON,
+// or null if v is nil. The JSON is validated and compacted; if it is invalid,
+// or MarshalJSON fails, the error is recorded.
+//
+// MarshalJSON allocates; prefer EncoderMarshaler for types of your own.
+func (enc *Encoder) WriteMarshalerKey(key []byte, v json.Marshaler, append_delim bool) {
+	enc.ObjectKey(key)
+	enc.Marshaler(v)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// MarshalerField writes the key and the JSON returned by v.MarshalJSON, or
+// null if v is nil. Delimiters are managed by the Encoder.
+func (enc *Encoder) MarshalerField(key []byte, v json.Marshaler) {
+	enc.WriteMarshalerKey(key, v, false)
+}
+
+// Marshaler writes the JSON returned by v.MarshalJSON, or null if v is nil,
+// as an array element.
+func (enc *Encoder) Marshaler(v json.Marshaler) {
+	if isNil(v) {
+		enc.Null()
+		return
+	}
+
+	if !enc.ok() {
+		return
+	}
+
+	b, err := v.MarshalJSON()
+	if err != nil {
+		enc.setErr(fmt.Errorf("encoder: %T: %w", v, err))
+		return
+	}
+
+	enc.writeRaw(b)
+}
+
+// WriteTextMarshalerKey writes the key and the text returned by
+// v.MarshalText as an escaped string, or null if v is nil. If MarshalText
+// fails, the error is recorded.
+func (enc *Encoder) WriteTextMarshalerKey(key []byte, v encoding.TextMarshaler, append_delim bool) {
+	enc.ObjectKey(key)
+	enc.TextMarshaler(v)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// TextMarshalerField writes the key and the text returned by v.MarshalText as
+// an escaped string, or null if v is nil. Delimiters are managed by the
+// Encoder.
+func (enc *Encoder) TextMarshalerField(key []byte, v encoding.TextMarshaler) {
+	enc.WriteTextMarshalerKey(key, v, false)
+}
+
+// TextMarshaler writes the text returned by v.MarshalText as an escaped
+// string, or null if v is nil, as an array element.
+func (enc *Encoder) TextMarshaler(v encoding.TextMarshaler) {
+	if isNil(v) {
+		enc.Null()
+		return
+	}
+
+	if !enc.ok() {
+		return
+	}
+
+	b, err := v.MarshalText()
+	if err != nil {
+		enc.setErr(fmt.Errorf("encoder: %T: %w", v, err))
+		return
+	}
+
+	writeString(enc, b)
+}
+
+// writeRaw writes the already encoded JSON value, compacted. If the value is
+// invalid, a *SyntaxError is recorded instead.
+func (enc *Encoder) writeRaw(raw []byte) {
+	enc.beginValue()
+	if !enc.ok() {
+		return
+	}
+
+	offset := enc.n + int64(enc.b.Len())
+	if err := json.Compact(enc.b, raw); err != nil {
+		enc.setErr(&SyntaxError{Msg: "invalid raw value: " + err.Error(), Offset: offset, Path: enc.path()})
+		return
+	}
+	enc.endValue()
+	enc.flush()
+}
+
+// isNil reports whether v is nil or holds a nil pointer.
+func isNil(v any) bool {
+	if v == nil {
+		return true
+	}
+
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
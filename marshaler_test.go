@@ -0,0 +1,113 @@
+package encoder
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type marshalerPoint struct {
+	X, Y int64
+}
+
+func (p *marshalerPoint) EncodeJSON(enc *Encoder) {
+	enc.ObjectStart()
+	enc.Int64Field([]byte("x"), p.X)
+	enc.Int64Field([]byte("y"), p.Y)
+	enc.ObjectEnd()
+}
+
+type rawMarshaler string
+
+func (r rawMarshaler) MarshalJSON() ([]byte, error) {
+	if r == "fail" {
+		return nil, errors.New("fail")
+	}
+	return []byte(r), nil
+}
+
+func TestEncoderWriteValueKey(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+
+	enc.ObjectStart()
+	enc.WriteValueKey([]byte("a"), &marshalerPoint{1, 2}, true)
+	enc.WriteValueKey([]byte("b"), nil, true)
+	enc.WriteValueKey([]byte("c"), (*marshalerPoint)(nil), false)
+	enc.ValueField([]byte("d"), &marshalerPoint{-1, 0})
+	enc.ObjectKey([]byte("e"))
+	enc.ArrayStart()
+	enc.Value(&marshalerPoint{})
+	enc.Value(&marshalerPoint{})
+	enc.ArrayEnd()
+	enc.ObjectEnd()
+	assert.Equal(t, `{"a":{"x":1,"y":2},"b":null,"c":null,"d":{"x":-1,"y":0},"e":[{"x":0,"y":0},{"x":0,"y":0}]}`, enc.b.String())
+}
+
+func TestEncoderWriteMarshalerKey(t *testing.T) {
+	t.Run("compacts", func(t *testing.T) {
+		enc := GetEncoder(nil)
+		defer enc.Release()
+
+		enc.ObjectStart()
+		enc.WriteMarshalerKey([]byte("a"), rawMarshaler("{ \"b\": [1, 2] }"), true)
+		enc.MarshalerField([]byte("c"), time.Unix(0, 0).UTC())
+		enc.ObjectEnd()
+		assert.Equal(t, `{"a":{"b":[1,2]},"c":"1970-01-01T00:00:00Z"}`, enc.b.String())
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		enc := GetEncoder(nil)
+		defer enc.Release()
+
+		enc.WriteMarshalerKey([]byte("a"), nil, false)
+		assert.Equal(t, `"a":null`, enc.b.String())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		enc := GetEncoder(nil)
+		defer enc.Release()
+
+		enc.ObjectStart()
+		enc.WriteMarshalerKey([]byte("a"), rawMarshaler("{"), false)
+		var serr *SyntaxError
+		assert.ErrorAs(t, enc.Err(), &serr)
+		assert.Equal(t, int64(5), serr.Offset)
+		assert.Equal(t, `{"a":`, enc.b.String())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		enc := GetEncoder(nil)
+		defer enc.Release()
+
+		enc.Marshaler(rawMarshaler("fail"))
+		assert.EqualError(t, enc.Err(), "encoder: encoder.rawMarshaler: fail")
+	})
+}
+
+func TestEncoderWriteTextMarshalerKey(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+
+	enc.ObjectStart()
+	enc.WriteTextMarshalerKey([]byte("ip"), net.IPv4(127, 0, 0, 1), true)
+	enc.TextMarshalerField([]byte("nil"), (*time.Time)(nil))
+	enc.ObjectEnd()
+	assert.Equal(t, `{"ip":"127.0.0.1","nil":null}`, enc.b.String())
+}
+
+func BenchmarkEncoderWriteValueKey(b *testing.B) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	key := []byte("/foo/bar")
+	p := &marshalerPoint{1, 2}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		enc.b.Reset()
+		enc.WriteValueKey(key, p, false)
+	}
+}
@@ -0,0 +1,184 @@
+package encoder
+
+import (
+	"database/sql"
+)
+
+// WriteInt64PtrKey writes the key and the value, or null if value is nil.
+func (enc *Encoder) WriteInt64PtrKey(key []byte, value *int64, append_delim bool) {
+	enc.ObjectKey(key)
+	enc.Int64Ptr(value)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteUint64PtrKey writes the key and the value, or null if value is nil.
+func (enc *Encoder) WriteUint64PtrKey(key []byte, value *uint64, append_delim bool) {
+	enc.ObjectKey(key)
+	enc.Uint64Ptr(value)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteFloat64PtrKey writes the key and the value, or null if value is nil.
+func (enc *Encoder) WriteFloat64PtrKey(key []byte, value *float64, append_delim bool) {
+	enc.ObjectKey(key)
+	enc.Float64Ptr(value)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteStringPtrKey writes the key and the escaped value, or null if value
+// is nil.
+func (enc *Encoder) WriteStringPtrKey(key []byte, value *string, append_delim bool) {
+	enc.ObjectKey(key)
+	enc.StringPtr(value)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteBoolPtrKey writes the key and the value, or null if value is nil.
+func (enc *Encoder) WriteBoolPtrKey(key []byte, value *bool, append_delim bool) {
+	enc.ObjectKey(key)
+	enc.BoolPtr(value)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteNullInt64Key writes the key and the value, or null if it is not
+// valid.
+func (enc *Encoder) WriteNullInt64Key(key []byte, value sql.NullInt64, append_delim bool) {
+	enc.ObjectKey(key)
+	enc.NullInt64(value)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteNullFloat64Key writes the key and the value, or null if it is not
+// valid.
+func (enc *Encoder) WriteNullFloat64Key(key []byte, value sql.NullFloat64, append_delim bool) {
+	enc.ObjectKey(key)
+	enc.NullFloat64(value)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteNullStringKey writes the key and the escaped value, or null if it is
+// not valid.
+func (enc *Encoder) WriteNullStringKey(key []byte, value sql.NullString, append_delim bool) {
+	enc.ObjectKey(key)
+	enc.NullString(value)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteNullTimeKey writes the key and the time as an ISO8601 value, or null
+// if it is not valid.
+func (enc *Encoder) WriteNullTimeKey(key []byte, value sql.NullTime, append_delim bool) {
+	enc.ObjectKey(key)
+	enc.NullTime(value)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// Int64Ptr writes the value as an array element, or null if value is nil.
+func (enc *Encoder) Int64Ptr(value *int64) {
+	if value == nil {
+		enc.Null()
+		return
+	}
+	enc.writeInt64(*value, false)
+}
+
+// Uint64Ptr writes the value as an array element, or null if value is nil.
+func (enc *Encoder) Uint64Ptr(value *uint64) {
+	if value == nil {
+		enc.Null()
+		return
+	}
+	enc.writeUint64(*value, false)
+}
+
+// Float64Ptr writes the value as an array element, or null if value is nil.
+func (enc *Encoder) Float64Ptr(value *float64) {
+	if value == nil {
+		enc.Null()
+		return
+	}
+	enc.writeFloat64(*value, false)
+}
+
+// StringPtr writes the escaped value as an array element, or null if value
+// is nil.
+func (enc *Encoder) StringPtr(value *string) {
+	if value == nil {
+		enc.Null()
+		return
+	}
+	writeString(enc, *value)
+}
+
+// BoolPtr writes the value as an array element, or null if value is nil.
+func (enc *Encoder) BoolPtr(value *bool) {
+	if value == nil {
+		enc.Null()
+		return
+	}
+	enc.Bool(*value)
+}
+
+// NullInt64 writes the value as an array element, or null if it is not valid.
+func (enc *Encoder) NullInt64(value sql.NullInt64) {
+	if !value.Valid {
+		enc.Null()
+		return
+	}
+	enc.writeInt64(value.Int64, false)
+}
+
+// NullFloat64 writes the value as an array element, or null if it is not valid.
+func (enc *Encoder) NullFloat64(value sql.NullFloat64) {
+	if !value.Valid {
+		enc.Null()
+		return
+	}
+	enc.writeFloat64(value.Float64, false)
+}
+
+// NullString writes the escaped value as an array element, or null if it is
+// not valid.
+func (enc *Encoder) NullString(value sql.NullString) {
+	if !value.Valid {
+		enc.Null()
+		return
+	}
+	writeString(enc, value.String)
+}
+
+// NullTime writes the time as an ISO8601 array element, or null if it is not
+// valid.
+func (enc *Encoder) NullTime(value sql.NullTime) {
+	if !value.Valid {
+		enc.Null()
+		return
+	}
+	enc.writeTime(value.Time)
+}
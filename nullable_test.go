@@ -0,0 +1,72 @@
+package encoder
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncoderWritePtrKey(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	i, u, f, s, b := int64(-1), uint64(1), 0.5, `"s"`, true
+
+	enc.ObjectStart()
+	enc.WriteInt64PtrKey([]byte("i"), &i, true)
+	enc.WriteUint64PtrKey([]byte("u"), &u, true)
+	enc.WriteFloat64PtrKey([]byte("f"), &f, true)
+	enc.WriteStringPtrKey([]byte("s"), &s, true)
+	enc.WriteBoolPtrKey([]byte("b"), &b, false)
+	enc.ObjectEnd()
+	assert.Equal(t, `{"i":-1,"u":1,"f":0.5,"s":"\"s\"","b":true}`, enc.b.String())
+
+	enc.b.Reset()
+	enc.ObjectStart()
+	enc.WriteInt64PtrKey([]byte("i"), nil, true)
+	enc.WriteUint64PtrKey([]byte("u"), nil, true)
+	enc.WriteFloat64PtrKey([]byte("f"), nil, true)
+	enc.WriteStringPtrKey([]byte("s"), nil, true)
+	enc.WriteBoolPtrKey([]byte("b"), nil, false)
+	enc.ObjectEnd()
+	assert.Equal(t, `{"i":null,"u":null,"f":null,"s":null,"b":null}`, enc.b.String())
+}
+
+func TestEncoderWriteSQLNullKey(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	enc.c.UTCTimestamps = true
+
+	enc.ObjectStart()
+	enc.WriteNullInt64Key([]byte("i"), sql.NullInt64{Int64: -1, Valid: true}, true)
+	enc.WriteNullFloat64Key([]byte("f"), sql.NullFloat64{Float64: 0.5, Valid: true}, true)
+	enc.WriteNullStringKey([]byte("s"), sql.NullString{String: "s", Valid: true}, true)
+	enc.WriteNullTimeKey([]byte("t"), sql.NullTime{Time: time.Unix(0, 0), Valid: true}, false)
+	enc.ObjectEnd()
+	assert.Equal(t, `{"i":-1,"f":0.5,"s":"s","t":"1970-01-01T00:00:00+00"}`, enc.b.String())
+
+	enc.b.Reset()
+	enc.ObjectStart()
+	enc.WriteNullInt64Key([]byte("i"), sql.NullInt64{}, true)
+	enc.WriteNullFloat64Key([]byte("f"), sql.NullFloat64{}, true)
+	enc.WriteNullStringKey([]byte("s"), sql.NullString{}, true)
+	enc.WriteNullTimeKey([]byte("t"), sql.NullTime{}, false)
+	enc.ObjectEnd()
+	assert.Equal(t, `{"i":null,"f":null,"s":null,"t":null}`, enc.b.String())
+}
+
+func TestEncoderNullableElements(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	i, s := int64(-1), "s"
+
+	enc.ArrayStart()
+	enc.Int64Ptr(&i)
+	enc.Uint64Ptr(nil)
+	enc.StringPtr(&s)
+	enc.NullInt64(sql.NullInt64{Int64: 2, Valid: true})
+	enc.NullFloat64(sql.NullFloat64{})
+	enc.ArrayEnd()
+	assert.Equal(t, `[-1,null,"s",2,null]`, enc.b.String())
+}
@@ -0,0 +1,353 @@
+package encoder
+
+import (
+	"bytes"
+)
+
+// PrettyPrint will prettify the JSON in the encoder buffer.
+// It expects the buffer to have escaped strings and valid JSON.
+//
+// However, the buffer does not need the entire response object.
+// We are dependent upon the parent caller to provide a valid
+// json structure.
+//
+// An array or object is held back until it is known whether it is empty, or
+// with EncoderConfig.MaxWidth whether it fits on the line, so it may be
+// written by a later call.
+func (enc *Encoder) PrettyPrint() {
+	// a buffer to write the pretty print.
+	buf := prettyPool.Get().(*bytes.Buffer)
+	buf.Reset()
+
+	if buf.Cap() <= MAXBUFSIZE {
+		buf.Grow(MAXBUFSIZE - buf.Cap())
+	}
+
+	defer func() {
+		// cleanup the buffer.
+		buf.Reset()
+		if buf.Cap() <= MAXBUFSIZE {
+			prettyPool.Put(buf)
+		}
+	}()
+
+	for {
+		c, err := enc.b.ReadByte()
+
+		if err != nil || c == 0 {
+			break
+		}
+
+		enc.pretty(buf, c)
+	}
+
+	enc.col = enc.column(buf)
+
+	if buf.Len() > 0 {
+		// ensure the encoder buffer is reset.
+		enc.b.Reset()
+		// write the pretty print buffer into the encoder buffer.
+		buf.WriteTo(enc.b)
+	}
+}
+
+// pretty writes the byte c, prettified, to buf.
+func (enc *Encoder) pretty(buf *bytes.Buffer, c byte) {
+	if enc.d == 0 && c > space {
+		enc.t = true
+	}
+
+	if len(enc.il) > 0 {
+		enc.inline(buf, c)
+		return
+	}
+
+	// if writing a string, do nothing but color it.
+	in := enc.s
+	if enc.str(c) {
+		switch {
+		case enc.cl == nil:
+			buf.WriteByte(c)
+		case !in && enc.k && len(enc.pk) > 0 && enc.pk[len(enc.pk)-1] == lBrace:
+			enc.color(buf, enc.cl.Key)
+			buf.WriteByte(c)
+		case !in:
+			enc.color(buf, enc.cl.String)
+			buf.WriteByte(c)
+		case !enc.s:
+			buf.WriteByte(c)
+			enc.uncolor(buf)
+		default:
+			buf.WriteByte(c)
+		}
+		return
+	}
+
+	switch c {
+	case lBrace, lBracket: // {, [
+		enc.d++
+		enc.pk = append(enc.pk, c)
+		enc.k = true
+		if !enc.flat {
+			// hold the container back until it is known whether it is
+			// empty or fits on the line.
+			enc.il = append(enc.il[:0], c)
+			enc.iw = 2
+			if enc.c.MaxWidth > 0 {
+				enc.ic = enc.column(buf)
+			}
+			return
+		}
+		enc.punct(buf, c)
+		enc.newLine(buf, false)
+	case rBrace, rBracket: // }, ]
+		enc.d--
+		if len(enc.pk) > 0 {
+			enc.pk = enc.pk[:len(enc.pk)-1]
+		}
+		enc.newLine(buf, false)
+		enc.punct(buf, c)
+		enc.endContainer(buf)
+	case delim: // ,
+		enc.k = true
+		enc.punct(buf, c)
+		enc.newLine(buf, true)
+	case colon: // :
+		enc.k = false
+		enc.punct(buf, c)
+		if !enc.c.OmitColonSpace {
+			buf.WriteByte(space)
+		}
+	default:
+		switch {
+		case enc.cl == nil:
+		case c <= space:
+			enc.uncolor(buf)
+		case enc.u:
+		case c == '-' || c >= '0' && c <= '9':
+			enc.color(buf, enc.cl.Number)
+			enc.u = true
+		default:
+			enc.color(buf, enc.cl.Literal)
+			enc.u = true
+		}
+		buf.WriteByte(c)
+	}
+}
+
+// str tracks the strings of the pretty print and reports whether c is part
+// of one. An escape may be split across two buffers, so its state is kept in
+// enc.e.
+func (enc *Encoder) str(c byte) bool {
+	switch {
+	case !enc.s:
+		enc.s = c == quoteMark
+		return enc.s
+	case enc.e:
+		enc.e = false
+	case c == backslash:
+		enc.e = true
+	case c == quoteMark:
+		enc.s = false
+	}
+	return true
+}
+
+// punct writes the punctuation c.
+func (enc *Encoder) punct(buf *bytes.Buffer, c byte) {
+	enc.uncolor(buf)
+	if enc.cl == nil || enc.cl.Punctuation == "" {
+		buf.WriteByte(c)
+		return
+	}
+
+	buf.WriteString(enc.cl.Punctuation)
+	buf.WriteByte(c)
+	buf.WriteString(colorReset)
+}
+
+// color starts a colored token.
+func (enc *Encoder) color(buf *bytes.Buffer, color string) {
+	enc.uncolor(buf)
+	if color != "" {
+		enc.v = true
+		buf.WriteString(color)
+	}
+}
+
+// uncolor ends a colored token.
+func (enc *Encoder) uncolor(buf *bytes.Buffer) {
+	enc.u = false
+	if enc.v {
+		enc.v = false
+		buf.WriteString(colorReset)
+	}
+}
+
+// newLine writes a newline and the indentation. Inside a container written
+// on one line, it writes the space after a delimiter instead.
+func (enc *Encoder) newLine(buf *bytes.Buffer, sep bool) {
+	enc.uncolor(buf)
+	switch {
+	case !enc.flat:
+		enc.indentNewLine(buf)
+	case sep:
+		buf.WriteByte(space)
+	}
+}
+
+// endContainer is called after the end of an array or object has been written.
+func (enc *Encoder) endContainer(buf *bytes.Buffer) {
+	if enc.d == 0 && enc.c.TrailingNewline {
+		enc.t = false
+		buf.WriteByte(newLine)
+	}
+}
+
+// inline holds back the bytes of an array or object until it ends, holds
+// another array or object, or no longer fits on one line: only empty
+// containers do by default, and those within EncoderConfig.MaxWidth with it.
+// The memory held is bounded by MaxWidth.
+func (enc *Encoder) inline(buf *bytes.Buffer, c byte) {
+	if !enc.str(c) {
+		switch c {
+		case lBrace, lBracket:
+			// containers holding containers are never kept on one line.
+			enc.expand(buf)
+			enc.pretty(buf, c)
+			return
+		case rBrace, rBracket:
+			if enc.fits() {
+				enc.writeInline(buf, c)
+				return
+			}
+			enc.expand(buf)
+			enc.pretty(buf, c)
+			return
+		case delim:
+			enc.iw++
+		case colon:
+			if !enc.c.OmitColonSpace {
+				enc.iw++
+			}
+		}
+	}
+
+	enc.il = append(enc.il, c)
+	// count the width in runes, not bytes.
+	if c&0xc0 != 0x80 {
+		enc.iw++
+	}
+
+	if !enc.fits() {
+		enc.expand(buf)
+	}
+}
+
+// fits reports whether the held back container fits on one line.
+func (enc *Encoder) fits() bool {
+	if enc.c.MaxWidth > 0 {
+		return enc.ic+enc.iw <= enc.c.MaxWidth
+	}
+	return len(enc.il) == 1
+}
+
+// writeInline writes the held back container on one line, closed by c.
+func (enc *Encoder) writeInline(buf *bytes.Buffer, c byte) {
+	enc.flat = true
+	enc.expand(buf)
+	enc.pretty(buf, c)
+	enc.flat = false
+}
+
+// expand writes the held back container, indented unless it is written on
+// one line. Its bytes hold no containers, so they are written straight away.
+func (enc *Encoder) expand(buf *bytes.Buffer) {
+	il := enc.il
+	enc.il = enc.il[:0]
+
+	enc.punct(buf, il[0])
+	enc.newLine(buf, false)
+
+	enc.s, enc.e = false, false
+	for _, c := range il[1:] {
+		enc.pretty(buf, c)
+	}
+}
+
+// prettyEnd writes what PrettyPrint holds back at the end of the output: an
+// unfinished container, the end of a colored value and the trailing newline
+// of a top-level scalar value.
+func (enc *Encoder) prettyEnd() {
+	if len(enc.il) > 0 {
+		enc.expand(enc.b)
+	}
+
+	enc.uncolor(enc.b)
+
+	if enc.c.TrailingNewline && enc.t {
+		enc.t = false
+		enc.b.WriteByte(newLine)
+	}
+
+	enc.writeBuffer()
+}
+
+// column returns the column at the end of buf, which continues the last line
+// of the previous PrettyPrint.
+func (enc *Encoder) column(buf *bytes.Buffer) int {
+	b := buf.Bytes()
+
+	i := bytes.LastIndexByte(b, newLine)
+	if i < 0 {
+		return enc.col + width(b)
+	}
+	return width(b[i+1:])
+}
+
+// width returns the number of runes in b, not counting ANSI color sequences.
+func width(b []byte) int {
+	n := 0
+	for i := 0; i < len(b); i++ {
+		switch {
+		case b[i] == 0x1b:
+			// skip to the end of the sequence, i.e. \x1b[32m
+			for i < len(b) && b[i] != 'm' {
+				i++
+			}
+		case b[i]&0xc0 != 0x80:
+			n++
+		}
+	}
+	return n
+}
+
+// indentNewLine writes a newline, the prefix and the indentation of the
+// current depth.
+func (enc *Encoder) indentNewLine(buf *bytes.Buffer) {
+	buf.WriteByte(newLine)
+	buf.WriteString(enc.c.Prefix)
+
+	if enc.c.IndentString != "" || enc.c.EmptyIndent {
+		for d := 0; d < enc.d; d++ {
+			buf.WriteString(enc.c.IndentString)
+		}
+		return
+	}
+
+	spaces := INDENT
+
+	if enc.c.Indent != 0 {
+		spaces = 1
+	}
+
+	for d := 0; d < enc.d*spaces; d++ {
+		switch enc.c.Indent {
+		case 1:
+			buf.WriteByte(tab)
+		default:
+			buf.WriteByte(space)
+		}
+	}
+
+}
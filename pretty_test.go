@@ -0,0 +1,380 @@
+package encoder
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncoderPrettyPrint(t *testing.T) {
+	t.Run("empty buffer", func(t *testing.T) {
+		enc := GetEncoder(nil)
+		defer enc.Release()
+		enc.PrettyPrint()
+		assert.Equal(t, 0, enc.Len())
+	})
+
+	t.Run("empty array", func(t *testing.T) {
+		enc := GetEncoder(nil)
+		defer enc.Release()
+
+		enc.ObjectStart()
+		enc.ObjectKey([]byte("keys"))
+		enc.ArrayStart()
+		enc.ArrayEnd()
+		enc.ObjectEnd()
+		enc.PrettyPrint()
+		assert.Equal(t, "{\n    \"keys\": []\n}", string(enc.Bytes()))
+	})
+
+	t.Run("delim", func(t *testing.T) {
+		enc := GetEncoder(nil)
+		defer enc.Release()
+
+		enc.ObjectStart()
+		enc.ObjectKey([]byte("foo"))
+		enc.ArrayStart()
+		enc.ArrayEnd()
+		enc.Delim()
+		enc.ObjectKey([]byte("bar"))
+		enc.ArrayStart()
+		enc.ArrayEnd()
+		enc.ObjectEnd()
+		enc.PrettyPrint()
+		assert.Equal(t, "{\n    \"foo\": [],\n    \"bar\": []\n}", string(enc.Bytes()))
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		enc := GetEncoder(nil)
+		defer enc.Release()
+
+		enc.ObjectStart()
+		enc.ObjectKey([]byte("foo"))
+		enc.ArrayStart()
+		enc.ObjectStart()
+		enc.WriteUint32Key([]byte("bar"), 0, true)
+		enc.WriteUint32Key([]byte("baz"), 0, false)
+		enc.ObjectEnd()
+		enc.ArrayEnd()
+		enc.ObjectEnd()
+
+		enc.PrettyPrint()
+		assert.Equal(t, "{\n    \"foo\": [\n        {\n            \"bar\": 0,\n            \"baz\": 0\n        }\n    ]\n}", string(enc.Bytes()))
+	})
+
+	t.Run("tab mode", func(t *testing.T) {
+		enc := GetEncoder(nil)
+		enc.SetConfig(EncoderConfig{Indent: TAB_MODE})
+		defer enc.Release()
+
+		enc.ObjectStart()
+		enc.ObjectKey([]byte("keys"))
+		enc.ArrayStart()
+		enc.ArrayEnd()
+		enc.ObjectEnd()
+		enc.PrettyPrint()
+		assert.Equal(t, "{\n\t\"keys\": []\n}", string(enc.Bytes()))
+	})
+
+	t.Run("split buffer", func(t *testing.T) {
+		enc := GetEncoder(nil)
+		defer enc.Release()
+
+		enc.AppendBytes([]byte(`"timestamp`))
+		enc.PrettyPrint()
+		assert.Equal(t, `"timestamp`, string(enc.Bytes()))
+		enc.b.Reset()
+
+		enc.AppendBytes([]byte(`":"1970-01-01T01:00:00"`))
+		enc.PrettyPrint()
+		assert.Equal(t, `": "1970-01-01T01:00:00"`, string(enc.Bytes()))
+	})
+
+	t.Run("split string", func(t *testing.T) {
+		enc := GetEncoder(nil)
+		defer enc.Release()
+
+		enc.AppendBytes([]byte(`"foo{}[],:`))
+		enc.PrettyPrint()
+		assert.Equal(t, `"foo{}[],:`, string(enc.Bytes()))
+		enc.b.Reset()
+
+		enc.AppendBytes([]byte(`bar{}[],:"`))
+		enc.PrettyPrint()
+		assert.Equal(t, `bar{}[],:"`, string(enc.Bytes()))
+	})
+
+	t.Run("string", func(t *testing.T) {
+		enc := GetEncoder(nil)
+		defer enc.Release()
+		enc.EncodeKey([]byte("a{}/string[]:,"))
+		enc.PrettyPrint()
+		assert.Equal(t, `"a{}/string[]:,"`, string(enc.Bytes()))
+	})
+
+	t.Run("escaped quote", func(t *testing.T) {
+		enc := GetEncoder(nil)
+		defer enc.Release()
+
+		enc.ObjectStart()
+		enc.WriteStrKey([]byte(`a"b`), `"{,}"\`, true)
+		enc.WriteUint64Key([]byte("c"), 1, false)
+		enc.ObjectEnd()
+		enc.PrettyPrint()
+		assert.Equal(t, "{\n    \"a\\\"b\": \"\\\"{,}\\\"\\\\\",\n    \"c\": 1\n}", string(enc.Bytes()))
+	})
+
+	t.Run("split escape", func(t *testing.T) {
+		enc := GetEncoder(nil)
+		defer enc.Release()
+
+		enc.AppendBytes([]byte(`["a\`))
+		enc.PrettyPrint()
+		assert.Equal(t, "[\n    \"a\\", string(enc.Bytes()))
+		enc.b.Reset()
+
+		enc.AppendBytes([]byte(`",1]`))
+		enc.PrettyPrint()
+		assert.Equal(t, "\",1]", string(enc.Bytes()))
+	})
+}
+
+func TestEncoderPrettyPrintEmpty(t *testing.T) {
+	for name, parts := range map[string][]string{
+		"object":     {`{}`},
+		"array":      {`[]`},
+		"nested":     {`[{},[],{"a":[]},[{}]]`},
+		"split":      {`{"a":[`, `],"b":{`, `}}`},
+		"split each": {`[`, `{`, `}`, `,`, `[`, `]`, `]`},
+		"string":     {`["[]","{",`, `"}"]`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			src := strings.Join(parts, "")
+			var want bytes.Buffer
+			json.Indent(&want, []byte(src), "", "    ")
+			assert.Equal(t, want.String(), prettyPrint(t, EncoderConfig{}, parts...))
+		})
+	}
+}
+
+func TestEncoderPrettyPrintConfig(t *testing.T) {
+	src := `{"a":[1,"b"],"c":{"d":null,"e":"f,g"}}`
+
+	pretty := func(config EncoderConfig, src string) string {
+		var b bytes.Buffer
+		enc := GetWriterEncoder(&b)
+		config.Pretty = true
+		enc.SetConfig(config)
+		defer enc.Release()
+
+		enc.AppendBytes([]byte(src))
+		assert.NoError(t, enc.Close())
+		return b.String()
+	}
+
+	t.Run("indent and prefix", func(t *testing.T) {
+		var want bytes.Buffer
+		json.Indent(&want, []byte(src), ">", "\t  ")
+		assert.Equal(t, want.String(), pretty(EncoderConfig{IndentString: "\t  ", Prefix: ">"}, src))
+	})
+
+	t.Run("no indent", func(t *testing.T) {
+		var want bytes.Buffer
+		json.Indent(&want, []byte(src), ">", "")
+		assert.Equal(t, want.String(), pretty(EncoderConfig{EmptyIndent: true, Prefix: ">"}, src))
+	})
+
+	t.Run("indent string overrides mode", func(t *testing.T) {
+		got := pretty(EncoderConfig{Indent: TAB_MODE, IndentString: "  "}, `[1]`)
+		assert.Equal(t, "[\n  1\n]", got)
+	})
+
+	t.Run("omit colon space", func(t *testing.T) {
+		got := pretty(EncoderConfig{IndentString: " ", OmitColonSpace: true}, `{"a":1}`)
+		assert.Equal(t, "{\n \"a\":1\n}", got)
+	})
+
+	t.Run("trailing newline", func(t *testing.T) {
+		var want bytes.Buffer
+		je := json.NewEncoder(&want)
+		je.SetIndent("", "  ")
+		je.Encode(json.RawMessage(src))
+		je.Encode(json.RawMessage(`[2]`))
+
+		got := pretty(EncoderConfig{IndentString: "  ", TrailingNewline: true}, src+`[2]`)
+		assert.Equal(t, want.String(), got)
+	})
+
+	t.Run("trailing newline scalar", func(t *testing.T) {
+		assert.Equal(t, "\"a\"\n", pretty(EncoderConfig{TrailingNewline: true}, `"a"`))
+		assert.Equal(t, "", pretty(EncoderConfig{TrailingNewline: true}, ``))
+	})
+}
+
+func FuzzEncoderPrettyPrint(f *testing.F) {
+	f.Add([]byte(`{"a":[1,"b\\\"",{"c":null}],"d\"":true}`), 7)
+	f.Add([]byte(`["\\","\"]",[[1],2]]`), 3)
+	f.Add([]byte(`"\u0022,\\"`), 2)
+
+	f.Fuzz(func(t *testing.T, data []byte, split int) {
+		var src bytes.Buffer
+		if json.Compact(&src, data) != nil {
+			t.Skip()
+		}
+
+		var want bytes.Buffer
+		json.Indent(&want, src.Bytes(), "", "    ")
+
+		var got bytes.Buffer
+		enc := GetWriterEncoder(&got)
+		enc.SetConfig(EncoderConfig{Pretty: true})
+		defer enc.Release()
+
+		// write the JSON in two parts, split at an arbitrary offset.
+		if split < 0 {
+			split = -split
+		}
+		split %= src.Len() + 1
+		enc.AppendBytes(src.Bytes()[:split])
+		assert.NoError(t, enc.Write())
+		enc.AppendBytes(src.Bytes()[split:])
+		assert.NoError(t, enc.Close())
+
+		assert.Equal(t, want.String(), got.String())
+	})
+}
+
+func BenchmarkEncoderPrettyPrint(b *testing.B) {
+	enc := GetEncoder(nil)
+	key := []byte("foo")
+	defer enc.Release()
+
+	for i := 0; i < b.N; i++ {
+		enc.ObjectStart()
+		enc.ObjectKey(key)
+		enc.ArrayStart()
+		enc.ArrayEnd()
+		enc.ObjectEnd()
+		enc.PrettyPrint()
+		enc.b.Reset()
+	}
+}
+
+// prettyPrint writes src in parts through a pretty printing Encoder, so the
+// JSON is prettified over several buffers.
+func prettyPrint(t *testing.T, config EncoderConfig, parts ...string) string {
+	var b bytes.Buffer
+	enc := GetWriterEncoder(&b)
+	config.Pretty = true
+	enc.SetConfig(config)
+	defer enc.Release()
+
+	for _, part := range parts {
+		enc.AppendBytes([]byte(part))
+		assert.NoError(t, enc.Write())
+	}
+	assert.NoError(t, enc.Close())
+	return b.String()
+}
+
+func TestEncoderPrettyPrintMaxWidth(t *testing.T) {
+	config := EncoderConfig{IndentString: "  ", MaxWidth: 30}
+
+	t.Run("inline", func(t *testing.T) {
+		got := prettyPrint(t, config, `{"a":[1,2,3],"b":{"c":1,"d":"x,y"},"e":[]}`)
+		assert.Equal(t, "{\n  \"a\": [1, 2, 3],\n  \"b\": {\"c\": 1, \"d\": \"x,y\"},\n  \"e\": []\n}", got)
+	})
+
+	t.Run("too wide", func(t *testing.T) {
+		// "a": [1, 2, 3, 4, 5, 6, 7, 8] would end at column 31.
+		got := prettyPrint(t, config, `{"a":[1,2,3,4,5,6,7,8],"b":[1,2,3,4,5,6,7]}`)
+		assert.Equal(t, "{\n  \"a\": [\n    1,\n    2,\n    3,\n    4,\n    5,\n    6,\n    7,\n    8\n  ],\n"+
+			"  \"b\": [1, 2, 3, 4, 5, 6, 7]\n}", got)
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		got := prettyPrint(t, config, `[[1,[2]],{"a":{}}]`)
+		assert.Equal(t, "[\n  [\n    1,\n    [2]\n  ],\n  {\n    \"a\": {}\n  }\n]", got)
+	})
+
+	t.Run("runes", func(t *testing.T) {
+		got := prettyPrint(t, EncoderConfig{MaxWidth: 8}, `["äöüß"]`)
+		assert.Equal(t, `["äöüß"]`, got)
+	})
+
+	t.Run("escapes", func(t *testing.T) {
+		got := prettyPrint(t, config, `["\"]",1]`)
+		assert.Equal(t, `["\"]", 1]`, got)
+	})
+
+	t.Run("split", func(t *testing.T) {
+		got := prettyPrint(t, config, `{"a":[`, `1,2`, `,3],"b":{"c":1,"d"`, `:"x,y"},"e":[`, `]}`)
+		assert.Equal(t, "{\n  \"a\": [1, 2, 3],\n  \"b\": {\"c\": 1, \"d\": \"x,y\"},\n  \"e\": []\n}", got)
+	})
+
+	t.Run("column", func(t *testing.T) {
+		// the key of b is written by an earlier PrettyPrint than its value.
+		got := prettyPrint(t, EncoderConfig{IndentString: "  ", MaxWidth: 16}, `{"bbbbbbbbb":`, `[1,2]}`)
+		assert.Equal(t, "{\n  \"bbbbbbbbb\": [\n    1,\n    2\n  ]\n}", got)
+	})
+
+	t.Run("unfinished", func(t *testing.T) {
+		got := prettyPrint(t, config, `[1,2`)
+		assert.Equal(t, "[\n  1,\n  2", got)
+	})
+
+	t.Run("trailing newline", func(t *testing.T) {
+		got := prettyPrint(t, EncoderConfig{MaxWidth: 80, TrailingNewline: true}, `[1,2]`, `{"a":1}`)
+		assert.Equal(t, "[1, 2]\n{\"a\": 1}\n", got)
+	})
+}
+
+func FuzzEncoderPrettyPrintMaxWidth(f *testing.F) {
+	f.Add([]byte(`{"a":[1,"b\\\"",{"c":null}],"d\"":[true,false]}`), 7, 20)
+	f.Add([]byte(`[[1,2],[3,[4,5]],{"a":"\\\\"}]`), 3, 8)
+
+	f.Fuzz(func(t *testing.T, data []byte, split, width int) {
+		var src bytes.Buffer
+		if json.Compact(&src, data) != nil {
+			t.Skip()
+		}
+
+		if split < 0 {
+			split = -split
+		}
+		split %= src.Len() + 1
+		config := EncoderConfig{MaxWidth: width%100 + 1}
+
+		// the output does not depend on where the buffers are split, and it
+		// holds the same JSON.
+		want := prettyPrint(t, config, src.String())
+		got := prettyPrint(t, config, src.String()[:split], src.String()[split:])
+		assert.Equal(t, want, got)
+
+		var compact bytes.Buffer
+		assert.NoError(t, json.Compact(&compact, []byte(got)))
+		assert.Equal(t, src.String(), compact.String())
+
+		// colors do not change the layout.
+		config.Color = COLOR_ALWAYS
+		colored := prettyPrint(t, config, src.String()[:split], src.String()[split:])
+		assert.Equal(t, want, ansi.ReplaceAllString(colored, ""))
+	})
+}
+
+func BenchmarkEncoderPrettyPrintMaxWidth(b *testing.B) {
+	enc := GetEncoder(nil)
+	enc.SetConfig(EncoderConfig{Pretty: true, MaxWidth: 80})
+	defer enc.Release()
+	src := []byte(`{"foo":[1,2,3,4],"bar":{"a":1,"b":[5,6]}}`)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		enc.b.Reset()
+		enc.b.Write(src)
+		enc.PrettyPrint()
+	}
+}
@@ -0,0 +1,74 @@
+package encoder
+
+// WriteRawKey writes the key and the already encoded JSON value raw, i.e. a
+// cached fragment of a previous response.
+//
+// The fragment is written as is, unless EncoderConfig.ValidateRaw or Strict
+// is set, in which case it is validated and compacted, or Pretty is set, in
+// which case it is compacted so it is indented at the current depth like the
+// rest of the output.
+func (enc *Encoder) WriteRawKey(key []byte, raw []byte, append_delim bool) {
+	enc.ObjectKey(key)
+	enc.WriteRaw(raw)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// RawField writes the key and the already encoded JSON value raw. Delimiters
+// are managed by the Encoder.
+func (enc *Encoder) RawField(key []byte, raw []byte) {
+	enc.WriteRawKey(key, raw, false)
+}
+
+// WriteRaw writes the already encoded JSON value raw as an array element. See
+// WriteRawKey.
+func (enc *Encoder) WriteRaw(raw []byte) {
+	switch {
+	case enc.c.ValidateRaw || enc.c.Strict:
+		enc.writeRaw(raw)
+	case enc.c.Pretty:
+		enc.writeCompact(raw)
+	default:
+		enc.beginValue()
+		enc.appendBytes(raw)
+		enc.endValue()
+	}
+}
+
+// writeCompact writes the already encoded JSON value without insignificant
+// whitespace. The value is not validated.
+func (enc *Encoder) writeCompact(raw []byte) {
+	enc.beginValue()
+	if !enc.ok() {
+		return
+	}
+
+	enc.b.Grow(len(raw))
+	enc.b.Write(appendCompact(enc.b.AvailableBuffer(), raw))
+	enc.endValue()
+	enc.flush()
+}
+
+// appendCompact appends src to dst without the whitespace outside of
+// strings.
+func appendCompact(dst, src []byte) []byte {
+	str, esc := false, false
+
+	for _, c := range src {
+		switch {
+		case esc:
+			esc = false
+		case str && c == backslash:
+			esc = true
+		case c == quoteMark:
+			str = !str
+		case !str && (c == space || c == newLine || c == tab || c == '\r'):
+			continue
+		}
+		dst = append(dst, c)
+	}
+
+	return dst
+}
@@ -0,0 +1,140 @@
+package encoder
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var rawFragment = []byte("{\n  \"a\": [1, 2],\t\"b\" : \"x \\\" y\"\r\n}")
+
+func TestEncoderWriteRawKey(t *testing.T) {
+	t.Run("verbatim", func(t *testing.T) {
+		enc := GetEncoder(nil)
+		defer enc.Release()
+
+		enc.ObjectStart()
+		enc.WriteRawKey([]byte("raw"), []byte(`[1, 2]`), true)
+		enc.RawField([]byte("other"), []byte(`null`))
+		enc.ObjectEnd()
+		assert.Equal(t, `{"raw":[1, 2],"other":null}`, enc.b.String())
+	})
+
+	t.Run("element", func(t *testing.T) {
+		enc := GetEncoder(nil)
+		defer enc.Release()
+
+		enc.ArrayStart()
+		enc.WriteRaw([]byte(`1`))
+		enc.WriteRaw([]byte(`{"a":2}`))
+		enc.ArrayEnd()
+		assert.Equal(t, `[1,{"a":2}]`, enc.b.String())
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		enc := GetEncoder(nil)
+		enc.SetConfig(EncoderConfig{ValidateRaw: true})
+		defer enc.Release()
+
+		enc.ObjectStart()
+		enc.RawField([]byte("raw"), rawFragment)
+		enc.ObjectEnd()
+		assert.Equal(t, `{"raw":{"a":[1,2],"b":"x \" y"}}`, enc.b.String())
+		assert.NoError(t, enc.Err())
+	})
+
+	for name, config := range map[string]EncoderConfig{
+		"$":     {ValidateRaw: true},
+		"$.raw": {Strict: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			enc := GetEncoder(nil)
+			enc.SetConfig(config)
+			defer enc.Release()
+
+			enc.ObjectStart()
+			enc.RawField([]byte("ok"), []byte(`1`))
+			enc.RawField([]byte("raw"), []byte(`{"a":}`))
+			enc.ObjectEnd()
+
+			var serr *SyntaxError
+			assert.True(t, errors.As(enc.Err(), &serr))
+			assert.Equal(t, name, serr.Path)
+			assert.Equal(t, int64(len(`{"ok":1,"raw":`)), serr.Offset)
+		})
+	}
+
+	t.Run("pretty", func(t *testing.T) {
+		var b bytes.Buffer
+		enc := GetWriterEncoder(&b)
+		enc.SetConfig(EncoderConfig{Pretty: true})
+		defer enc.Release()
+
+		enc.ObjectStart()
+		enc.ObjectKey([]byte("list"))
+		enc.ArrayStart()
+		enc.WriteRaw(rawFragment)
+		enc.WriteRaw([]byte(" 2 "))
+		enc.ArrayEnd()
+		enc.ObjectEnd()
+		assert.NoError(t, enc.Close())
+
+		var want bytes.Buffer
+		json.Indent(&want, []byte(`{"list":[`+string(rawFragment)+`,2]}`), "", "    ")
+		assert.Equal(t, want.String(), b.String())
+	})
+
+	t.Run("pretty flush", func(t *testing.T) {
+		var b bytes.Buffer
+		enc := GetWriterEncoder(&b)
+		enc.SetConfig(EncoderConfig{Pretty: true})
+		defer enc.Release()
+
+		raw := "[" + strings.Repeat("1,\n", MAXBUFSIZE) + "1]"
+		enc.ObjectStart()
+		enc.RawField([]byte("raw"), []byte(raw))
+		enc.ObjectEnd()
+		assert.NoError(t, enc.Close())
+		assert.Greater(t, enc.f, int64(1))
+
+		var want bytes.Buffer
+		json.Indent(&want, []byte(`{"raw":`+raw+`}`), "", "    ")
+		assert.Equal(t, want.String(), b.String())
+	})
+}
+
+func TestAppendCompact(t *testing.T) {
+	for src, want := range map[string]string{
+		"":                        "",
+		" 1 ":                     "1",
+		"{ \"a b\" : [ 1 , 2 ] }": `{"a b":[1,2]}`,
+		`[ "\\", " " ]`:           `["\\"," "]`,
+		`[ "\" ", " " ]`:          `["\" "," "]`,
+	} {
+		assert.Equal(t, want, string(appendCompact(nil, []byte(src))), src)
+	}
+}
+
+func BenchmarkEncoderWriteRaw(b *testing.B) {
+	for name, config := range map[string]EncoderConfig{
+		"verbatim": {},
+		"compact":  {Pretty: true},
+		"validate": {ValidateRaw: true},
+	} {
+		b.Run(name, func(b *testing.B) {
+			enc := GetEncoder(nil)
+			enc.SetConfig(config)
+			defer enc.Release()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				enc.b.Reset()
+				enc.WriteRaw(rawFragment)
+			}
+		})
+	}
+}
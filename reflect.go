@@ -0,0 +1,623 @@
+package encoder
+
+import (
+	"cmp"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The reflection based encoder writes arbitrary Go values with the same
+// primitives as the hand-written encoders. The first time a type is seen, an
+// encoding plan is built from its json struct tags and cached; afterwards
+// encoding a value only walks the plan, without allocating for structs,
+// slices and scalars. Maps allocate to sort their keys.
+//
+// The tags are read like encoding/json does: `json:"name,omitempty,string"`,
+// and `json:"-"` skips a field. Values are written with the Encoder config,
+// i.e. floats are rounded and time.Time values are formatted as ISO8601.
+
+// maxDepth is the nesting depth at which the reflection based encoder assumes
+// a cycle, through pointers, maps, slices or interfaces, and gives up.
+const maxDepth = 1000
+
+type encoderFunc func(enc *Encoder, v reflect.Value)
+
+var (
+	plans sync.Map // map[reflect.Type]encoderFunc
+
+	timeType             = reflect.TypeFor[time.Time]()
+	numberType           = reflect.TypeFor[json.Number]()
+	encoderMarshalerType = reflect.TypeFor[EncoderMarshaler]()
+	jsonMarshalerType    = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType    = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// UnsupportedTypeError is recorded when a value of a type which has no JSON
+// representation, i.e. a channel or a function, is encoded.
+type UnsupportedTypeError struct {
+	Type reflect.Type
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	return "encoder: unsupported type: " + e.Type.String()
+}
+
+// Encode writes v, as an array element or after ObjectKey, using reflection.
+// Returns the first error encountered by the encoder, if any.
+func (enc *Encoder) Encode(v any) error {
+	enc.encode(v)
+	return enc.err
+}
+
+// WriteStructKey writes the key and v, usually a struct, using reflection.
+func (enc *Encoder) WriteStructKey(key []byte, v any, append_delim bool) {
+	enc.ObjectKey(key)
+	enc.encode(v)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// StructField writes the key and v, usually a struct, using reflection.
+// Delimiters are managed by the Encoder.
+func (enc *Encoder) StructField(key []byte, v any) {
+	enc.WriteStructKey(key, v, false)
+}
+
+func (enc *Encoder) encode(v any) {
+	if v == nil {
+		enc.Null()
+		return
+	}
+
+	rv := reflect.ValueOf(v)
+	typeEncoder(rv.Type())(enc, rv)
+}
+
+// typeEncoder returns the cached encoder for t, building it on first use.
+func typeEncoder(t reflect.Type) encoderFunc {
+	if f, ok := plans.Load(t); ok {
+		return f.(encoderFunc)
+	}
+
+	// recursive types refer to themselves while their encoder is being
+	// built. hand out an indirect encoder which waits for the real one.
+	var (
+		wg sync.WaitGroup
+		f  encoderFunc
+	)
+	wg.Add(1)
+	fi, loaded := plans.LoadOrStore(t, encoderFunc(func(enc *Encoder, v reflect.Value) {
+		wg.Wait()
+		f(enc, v)
+	}))
+	if loaded {
+		return fi.(encoderFunc)
+	}
+
+	f = newTypeEncoder(t, true)
+	wg.Done()
+	plans.Store(t, f)
+	return f
+}
+
+// newTypeEncoder builds the encoder for t. If allowAddr is set, values whose
+// pointer implements one of the marshaler interfaces use it when addressable.
+func newTypeEncoder(t reflect.Type, allowAddr bool) encoderFunc {
+	switch t {
+	case timeType:
+		return timeEncoder
+	case numberType:
+		return numberEncoder
+	}
+
+	if t.Kind() != reflect.Pointer && allowAddr && implementsMarshaler(reflect.PointerTo(t)) {
+		return condAddrEncoder(newTypeEncoder(reflect.PointerTo(t), false), newTypeEncoder(t, false))
+	}
+
+	switch {
+	case t.Implements(encoderMarshalerType):
+		return encoderMarshalerEncoder
+	case t.Implements(jsonMarshalerType):
+		return jsonMarshalerEncoder
+	case t.Implements(textMarshalerType):
+		return textMarshalerEncoder
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return boolEncoder
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return intEncoder
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return uintEncoder
+	case reflect.Float32, reflect.Float64:
+		return floatEncoder
+	case reflect.String:
+		return stringEncoder
+	case reflect.Interface:
+		return interfaceEncoder
+	case reflect.Struct:
+		return newStructEncoder(t)
+	case reflect.Map:
+		return newMapEncoder(t)
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 && !implementsMarshaler(reflect.PointerTo(t.Elem())) {
+			return bytesEncoder
+		}
+		return newArrayEncoder(t)
+	case reflect.Array:
+		return newArrayEncoder(t)
+	case reflect.Pointer:
+		return newPtrEncoder(t)
+	default:
+		return unsupportedTypeEncoder
+	}
+}
+
+func implementsMarshaler(t reflect.Type) bool {
+	return t.Implements(encoderMarshalerType) || t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType)
+}
+
+func condAddrEncoder(addr, value encoderFunc) encoderFunc {
+	return func(enc *Encoder, v reflect.Value) {
+		if v.CanAddr() {
+			addr(enc, v.Addr())
+		} else {
+			value(enc, v)
+		}
+	}
+}
+
+func encoderMarshalerEncoder(enc *Encoder, v reflect.Value) {
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		enc.Null()
+		return
+	}
+	v.Interface().(EncoderMarshaler).EncodeJSON(enc)
+}
+
+func jsonMarshalerEncoder(enc *Encoder, v reflect.Value) {
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		enc.Null()
+		return
+	}
+	enc.Marshaler(v.Interface().(json.Marshaler))
+}
+
+func textMarshalerEncoder(enc *Encoder, v reflect.Value) {
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		enc.Null()
+		return
+	}
+	enc.TextMarshaler(v.Interface().(encoding.TextMarshaler))
+}
+
+func timeEncoder(enc *Encoder, v reflect.Value) {
+	if v.CanAddr() {
+		// avoids copying the time to the heap.
+		enc.writeTime(*v.Addr().Interface().(*time.Time))
+		return
+	}
+	enc.writeTime(v.Interface().(time.Time))
+}
+
+func numberEncoder(enc *Encoder, v reflect.Value) {
+	n := v.String()
+	if n == "" {
+		n = "0"
+	}
+
+	if !validNumber(n) {
+		enc.setErr(fmt.Errorf("encoder: invalid number literal %q", n))
+		return
+	}
+
+	enc.beginValue()
+	writeEscaped(enc, n)
+	enc.endValue()
+}
+
+func boolEncoder(enc *Encoder, v reflect.Value) {
+	enc.Bool(v.Bool())
+}
+
+func intEncoder(enc *Encoder, v reflect.Value) {
+	enc.writeInt64(v.Int(), false)
+}
+
+func uintEncoder(enc *Encoder, v reflect.Value) {
+	enc.writeUint64(v.Uint(), false)
+}
+
+func floatEncoder(enc *Encoder, v reflect.Value) {
+	enc.writeFloat64(v.Float(), false)
+}
+
+func stringEncoder(enc *Encoder, v reflect.Value) {
+	writeString(enc, v.String())
+}
+
+func bytesEncoder(enc *Encoder, v reflect.Value) {
+	if v.IsNil() {
+		enc.Null()
+		return
+	}
+
+	enc.beginValue()
+	if enc.ok() {
+		enc.b.Grow(base64.StdEncoding.EncodedLen(v.Len()) + 2)
+		enc.b.WriteByte(quoteMark)
+		enc.b.Write(base64.StdEncoding.AppendEncode(enc.b.AvailableBuffer(), v.Bytes()))
+		enc.b.WriteByte(quoteMark)
+		enc.flush()
+	}
+	enc.endValue()
+}
+
+func interfaceEncoder(enc *Encoder, v reflect.Value) {
+	if v.IsNil() {
+		enc.Null()
+		return
+	}
+	if cycle(enc, v) {
+		return
+	}
+	e := v.Elem()
+	enc.ptr++
+	typeEncoder(e.Type())(enc, e)
+	enc.ptr--
+}
+
+func unsupportedTypeEncoder(enc *Encoder, v reflect.Value) {
+	enc.setErr(&UnsupportedTypeError{Type: v.Type()})
+}
+
+// quotedEncoder writes scalars as JSON strings, for the `string` tag option.
+func quotedEncoder(enc *Encoder, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Bool:
+		enc.EncodedBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		enc.writeInt64(v.Int(), true)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		enc.writeUint64(v.Uint(), true)
+	case reflect.Float32, reflect.Float64:
+		enc.writeFloat64(v.Float(), true)
+	case reflect.String:
+		enc.EncodedStr(v.String())
+	}
+}
+
+// field is a struct field in an encoding plan.
+type field struct {
+	name      string // the JSON name.
+	key       Key    // the quoted and escaped name followed by a colon.
+	index     []int  // the index sequence for reflect.Value.FieldByIndex.
+	omitEmpty bool
+	enc       encoderFunc
+}
+
+func newStructEncoder(t reflect.Type) encoderFunc {
+	fields := structFields(t)
+
+	return func(enc *Encoder, v reflect.Value) {
+		enc.ObjectStart()
+		for i := range fields {
+			f := &fields[i]
+
+			fv, err := v.FieldByIndexErr(f.index)
+			if err != nil {
+				// the field is promoted through a nil embedded pointer.
+				continue
+			}
+			if f.omitEmpty && isEmptyValue(fv) {
+				continue
+			}
+
+			enc.writeKey(f.key)
+			f.enc(enc, fv)
+		}
+		enc.ObjectEnd()
+	}
+}
+
+// structFields returns the fields of t to encode, in order. The fields of
+// untagged embedded structs are promoted. Name conflicts are resolved like
+// encoding/json does: the shallowest field wins, a tagged field wins over
+// untagged ones at the same depth, and otherwise all are dropped.
+func structFields(t reflect.Type) []field {
+	var (
+		fields []field
+		tagged []bool
+		path   []reflect.Type // embedded struct types being walked.
+		walk   func(t reflect.Type, index []int)
+	)
+
+	walk = func(t reflect.Type, index []int) {
+		path = append(path, t)
+		defer func() { path = path[:len(path)-1] }()
+
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+
+			ft := sf.Type
+			if sf.Anonymous && ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+
+			// embedded structs of unexported types may have exported fields.
+			if !sf.IsExported() && !(sf.Anonymous && ft.Kind() == reflect.Struct) {
+				continue
+			}
+
+			tag := sf.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+
+			name, opts, _ := strings.Cut(tag, ",")
+			idx := append(slices.Clip(index), i)
+
+			if sf.Anonymous && ft.Kind() == reflect.Struct && name == "" {
+				if !slices.Contains(path, ft) {
+					walk(ft, idx)
+				}
+				continue
+			}
+
+			if name == "" {
+				name = sf.Name
+			}
+
+			f := field{
+				name:      name,
+				index:     idx,
+				omitEmpty: hasOption(opts, "omitempty"),
+				enc:       typeEncoder(sf.Type),
+			}
+
+			if hasOption(opts, "string") {
+				switch sf.Type.Kind() {
+				case reflect.Bool, reflect.String,
+					reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+					reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+					reflect.Float32, reflect.Float64:
+					f.enc = quotedEncoder
+				}
+			}
+
+			f.key = NewKey(name)
+			fields = append(fields, f)
+			tagged = append(tagged, tag != "")
+		}
+	}
+	walk(t, nil)
+
+	return dominantFields(fields, tagged)
+}
+
+// dominantFields drops the fields whose names conflict, keeping the dominant
+// one if there is one.
+func dominantFields(fields []field, tagged []bool) []field {
+	keep := make([]field, 0, len(fields))
+
+	for i, f := range fields {
+		dominant := true
+		for j, g := range fields {
+			if i == j || f.name != g.name {
+				continue
+			}
+			switch {
+			case len(g.index) < len(f.index):
+				dominant = false
+			case len(g.index) == len(f.index) && (tagged[j] || !tagged[i]):
+				dominant = false
+			}
+		}
+
+		if dominant {
+			keep = append(keep, f)
+		}
+	}
+
+	return keep
+}
+
+// hasOption reports whether the comma separated tag options contain opt.
+func hasOption(opts, opt string) bool {
+	for opts != "" {
+		var o string
+		o, opts, _ = strings.Cut(opts, ",")
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+// newMapEncoder writes maps as objects, sorted by key. Keys must be strings
+// or integers.
+func newMapEncoder(t reflect.Type) encoderFunc {
+	switch t.Key().Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+	default:
+		return unsupportedTypeEncoder
+	}
+
+	elem := typeEncoder(t.Elem())
+
+	return func(enc *Encoder, v reflect.Value) {
+		if v.IsNil() {
+			enc.Null()
+			return
+		}
+		if cycle(enc, v) {
+			return
+		}
+
+		type member struct {
+			key   string
+			value reflect.Value
+		}
+
+		members := make([]member, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			k := iter.Key()
+			var key string
+			switch k.Kind() {
+			case reflect.String:
+				key = k.String()
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				key = strconv.FormatInt(k.Int(), 10)
+			default:
+				key = strconv.FormatUint(k.Uint(), 10)
+			}
+			members = append(members, member{key, iter.Value()})
+		}
+		slices.SortFunc(members, func(a, b member) int {
+			return cmp.Compare(a.key, b.key)
+		})
+
+		enc.ObjectStart()
+		for _, m := range members {
+			if !enc.ok() {
+				break
+			}
+			writeObjectKey(enc, m.key)
+			elem(enc, m.value)
+		}
+		enc.ObjectEnd()
+	}
+}
+
+func newArrayEncoder(t reflect.Type) encoderFunc {
+	elem := typeEncoder(t.Elem())
+
+	return func(enc *Encoder, v reflect.Value) {
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			enc.Null()
+			return
+		}
+		if cycle(enc, v) {
+			return
+		}
+
+		enc.ArrayStart()
+		for i := 0; i < v.Len(); i++ {
+			if !enc.ok() {
+				break
+			}
+			elem(enc, v.Index(i))
+		}
+		enc.ArrayEnd()
+	}
+}
+
+func newPtrEncoder(t reflect.Type) encoderFunc {
+	elem := typeEncoder(t.Elem())
+
+	return func(enc *Encoder, v reflect.Value) {
+		if v.IsNil() {
+			enc.Null()
+			return
+		}
+
+		if cycle(enc, v) {
+			return
+		}
+		enc.ptr++
+		elem(enc, v.Elem())
+		enc.ptr--
+	}
+}
+
+// cycle records an error and reports true if v is nested beyond maxDepth,
+// which is assumed to be a cycle. It is checked wherever the encoder recurses.
+// Pointers and interfaces write no object or array, so they are counted
+// apart from the nesting state, i.e. for x = &x.
+func cycle(enc *Encoder, v reflect.Value) bool {
+	if len(enc.st)+enc.ptr <= maxDepth {
+		return false
+	}
+	enc.setErr(fmt.Errorf("encoder: encountered a cycle via %s", v.Type()))
+	return true
+}
+
+// validNumber reports whether s is a valid JSON number literal.
+func validNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	// optional -
+	if s[0] == '-' {
+		s = s[1:]
+		if s == "" {
+			return false
+		}
+	}
+
+	// digits
+	switch {
+	case s[0] == '0':
+		s = s[1:]
+	case '1' <= s[0] && s[0] <= '9':
+		s = s[1:]
+		for len(s) > 0 && '0' <= s[0] && s[0] <= '9' {
+			s = s[1:]
+		}
+	default:
+		return false
+	}
+
+	// . followed by 1 or more digits.
+	if len(s) >= 2 && s[0] == '.' && '0' <= s[1] && s[1] <= '9' {
+		s = s[2:]
+		for len(s) > 0 && '0' <= s[0] && s[0] <= '9' {
+			s = s[1:]
+		}
+	}
+
+	// e or E followed by an optional - or + and 1 or more digits.
+	if len(s) >= 2 && (s[0] == 'e' || s[0] == 'E') {
+		s = s[1:]
+		if s[0] == '+' || s[0] == '-' {
+			s = s[1:]
+			if s == "" {
+				return false
+			}
+		}
+		for len(s) > 0 && '0' <= s[0] && s[0] <= '9' {
+			s = s[1:]
+		}
+	}
+
+	return s == ""
+}
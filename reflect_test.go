@@ -0,0 +1,222 @@
+package encoder
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type reflectInner struct {
+	A int `json:"a"`
+	B string
+}
+
+type reflectEmbedded struct {
+	E uint8 `json:"e"`
+}
+
+type reflectOuter struct {
+	reflectEmbedded
+	Int      int64             `json:"int"`
+	Uint     uint32            `json:"uint,omitempty"`
+	Float    float64           `json:"float"`
+	Str      string            `json:"str,omitempty"`
+	Bool     bool              `json:"bool"`
+	Quoted   int               `json:"quoted,string"`
+	QStr     string            `json:"qstr,string"`
+	Skip     string            `json:"-"`
+	Inner    reflectInner      `json:"inner"`
+	Ptr      *reflectInner     `json:"ptr"`
+	NilPtr   *reflectInner     `json:"nil_ptr,omitempty"`
+	Slice    []reflectInner    `json:"slice"`
+	NilSlice []int             `json:"nil_slice"`
+	Array    [2]int            `json:"array"`
+	Map      map[string]int    `json:"map"`
+	IntMap   map[int]string    `json:"int_map"`
+	Bytes    []byte            `json:"bytes"`
+	Any      any               `json:"any"`
+	Number   json.Number       `json:"number"`
+	IP       net.IP            `json:"ip"`
+	Raw      json.RawMessage   `json:"raw"`
+	Point    *marshalerPoint   `json:"point"`
+	Points   []*marshalerPoint `json:"points,omitempty"`
+	private  int
+}
+
+func TestEncoderEncode(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+
+	v := reflectOuter{
+		reflectEmbedded: reflectEmbedded{E: 7},
+		Int:             -1,
+		Float:           0.5,
+		Bool:            true,
+		Quoted:          42,
+		QStr:            `a"b`,
+		Skip:            "skip",
+		Inner:           reflectInner{1, "x"},
+		Ptr:             &reflectInner{2, "y\n"},
+		Slice:           []reflectInner{{3, "z"}},
+		Array:           [2]int{4, 5},
+		Map:             map[string]int{"b": 2, "a": 1},
+		IntMap:          map[int]string{10: "ten", 9: "nine"},
+		Bytes:           []byte("hello"),
+		Any:             []any{1.5, "s", nil},
+		Number:          "1e3",
+		IP:              net.IPv4(127, 0, 0, 1),
+		Raw:             json.RawMessage(`{ "r": [1, 2] }`),
+		private:         1,
+	}
+
+	assert.NoError(t, enc.Encode(v))
+
+	// encoding/json agrees, except for the newline which the Encoder replaces
+	// with a space.
+	want, err := json.Marshal(v)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(want), `{"e":7,"int":-1,"float":0.5,"bool":true,"quoted":"42","qstr":"\"a\\\"b\"",`+
+		`"inner":{"a":1,"B":"x"},"ptr":{"a":2,"B":"y\n"},"slice":[{"a":3,"B":"z"}],"nil_slice":null,`+
+		`"array":[4,5],"map":{"a":1,"b":2},"int_map":{"10":"ten","9":"nine"},"bytes":"aGVsbG8=",`+
+		`"any":[1.5,"s",null],"number":1e3,"ip":"127.0.0.1","raw":{"r":[1,2]},"point":null}`)
+
+	assert.Equal(t, `{"e":7,"int":-1,"float":0.5,"bool":true,"quoted":"42","qstr":"\"a\\\"b\"",`+
+		`"inner":{"a":1,"B":"x"},"ptr":{"a":2,"B":"y "},"slice":[{"a":3,"B":"z"}],"nil_slice":null,`+
+		`"array":[4,5],"map":{"a":1,"b":2},"int_map":{"10":"ten","9":"nine"},"bytes":"aGVsbG8=",`+
+		`"any":[1.5,"s",null],"number":1e3,"ip":"127.0.0.1","raw":{"r":[1,2]},"point":null}`, enc.b.String())
+}
+
+func TestEncoderWriteStructKey(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+
+	enc.ObjectStart()
+	enc.WriteStructKey([]byte("a"), reflectInner{1, "x"}, true)
+	enc.WriteStructKey([]byte("b"), nil, false)
+	enc.StructField([]byte("c"), &marshalerPoint{1, 2})
+	enc.StructField([]byte("d"), []uint16{1, 2})
+	enc.ObjectEnd()
+	assert.Equal(t, `{"a":{"a":1,"B":"x"},"b":null,"c":{"x":1,"y":2},"d":[1,2]}`, enc.b.String())
+}
+
+func TestEncoderEncodeTime(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	enc.c.UTCTimestamps = true
+
+	v := struct {
+		T time.Time `json:"t"`
+	}{time.Unix(0, 0)}
+	assert.NoError(t, enc.Encode(&v))
+	assert.NoError(t, enc.Encode(v))
+	assert.Equal(t, `{"t":"1970-01-01T00:00:00+00"}{"t":"1970-01-01T00:00:00+00"}`, enc.b.String())
+}
+
+type reflectConflict struct {
+	reflectInner
+	reflectEmbedded `json:"embedded"`
+	A               int `json:"B"`
+}
+
+func TestEncoderEncodeFieldConflicts(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+
+	v := reflectConflict{reflectInner{1, "x"}, reflectEmbedded{2}, 3}
+	assert.NoError(t, enc.Encode(v))
+
+	want, _ := json.Marshal(v)
+	assert.Equal(t, string(want), enc.b.String())
+}
+
+type reflectNode struct {
+	Value int          `json:"value"`
+	Next  *reflectNode `json:"next,omitempty"`
+}
+
+func TestEncoderEncodeRecursive(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+
+	v := &reflectNode{1, &reflectNode{2, nil}}
+	assert.NoError(t, enc.Encode(v))
+	assert.Equal(t, `{"value":1,"next":{"value":2}}`, enc.b.String())
+}
+
+func TestEncoderEncodeErrors(t *testing.T) {
+	t.Run("unsupported", func(t *testing.T) {
+		enc := GetEncoder(nil)
+		defer enc.Release()
+
+		var uerr *UnsupportedTypeError
+		assert.ErrorAs(t, enc.Encode(struct{ C chan int }{}), &uerr)
+	})
+
+	t.Run("invalid number", func(t *testing.T) {
+		enc := GetEncoder(nil)
+		defer enc.Release()
+
+		assert.Error(t, enc.Encode(json.Number("1x")))
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		v := &reflectNode{Value: 1}
+		v.Next = v
+
+		m := map[string]any{}
+		m["a"] = m
+
+		a := []any{nil}
+		a[0] = a
+
+		// a cycle through pointers and interfaces only.
+		var x any
+		x = &x
+
+		// the output of a cycle overflows the buffer, so it is flushed.
+		for _, v := range []any{v, m, a, x} {
+			enc := GetWriterEncoder(io.Discard)
+			assert.ErrorContains(t, enc.Encode(v), "cycle")
+			enc.Release()
+		}
+	})
+}
+
+func TestEncoderEncodeAllocs(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+
+	v := &reflectInner{1, "x"}
+	enc.Encode(v)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		enc.b.Reset()
+		enc.Encode(v)
+	})
+	assert.Equal(t, float64(0), allocs)
+}
+
+func TestValidNumber(t *testing.T) {
+	for _, n := range []string{"0", "-0", "1", "-12", "1.5", "1e3", "1E+3", "1.5e-3"} {
+		assert.True(t, validNumber(n), n)
+	}
+	for _, n := range []string{"", "-", "01", "1.", ".5", "1e", "1e+", "+1", "0x1", "1.5.5"} {
+		assert.False(t, validNumber(n), n)
+	}
+}
+
+func BenchmarkEncoderEncode(b *testing.B) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	v := &reflectInner{1, "x"}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		enc.b.Reset()
+		enc.Encode(v)
+	}
+}
@@ -0,0 +1,376 @@
+package encoder
+
+import (
+	"strconv"
+	"sync"
+)
+
+// maxScanDepth limits the nesting of the JSON read by the scanner, so the
+// memory it uses is bounded.
+const maxScanDepth = 10000
+
+var scanPool = sync.Pool{
+	New: func() interface{} {
+		return new(scanner)
+	},
+}
+
+// results of scanner.scan.
+const (
+	scanContinue = iota // the byte is part of a value.
+	scanSpace           // the byte is insignificant whitespace.
+	scanNext            // the byte begins a top-level value following another.
+	scanError           // the byte is invalid, see scanner.err.
+)
+
+// states of the scanner.
+const (
+	stateBegin      = iota // top level, before the first value.
+	stateEnd               // top level, after a value.
+	stateSep               // top level, after the whitespace following a value.
+	stateValue             // a value.
+	stateValueOrEnd        // a value or ], after [.
+	stateKeyOrEnd          // a key or }, after {.
+	stateKey               // a key, after , in an object.
+	stateColon             // :, after a key.
+	stateNext              // , or the end of the container, after a value.
+	stateString            // in a string.
+	stateEscape            // after \ in a string.
+	stateHex               // in the hex digits of \uXXXX.
+	stateNeg               // after - of a number.
+	stateZero              // after the leading 0 of a number.
+	stateInt               // in the integer digits of a number.
+	stateDot               // after the . of a number.
+	stateFrac              // in the fraction digits of a number.
+	stateExp               // after the e of a number.
+	stateExpSign           // after the sign of the exponent.
+	stateExpInt            // in the exponent digits.
+	stateLiteral           // in true, false or null.
+)
+
+// scanner validates JSON a byte at a time, so it can be read as a stream in
+// constant memory. A stream of whitespace separated top-level values is
+// accepted, i.e. NDJSON.
+type scanner struct {
+	state int
+	st    []byte // kinds of the open containers.
+	key   bool   // the string is a key.
+	hex   int    // number of hex digits left in \uXXXX.
+	lit   string // the literal being read.
+	li    int    // number of bytes of lit read.
+	n     int64  // number of bytes read.
+	err   *SyntaxError
+}
+
+// reset the scanner to read a new stream.
+func (s *scanner) reset() {
+	s.state = stateBegin
+	s.st = s.st[:0]
+	s.key = false
+	s.hex = 0
+	s.n = 0
+	s.err = nil
+}
+
+// scan reads the byte c.
+func (s *scanner) scan(c byte) int {
+	r := s.step(c)
+	s.n++
+	return r
+}
+
+// eof is called at the end of the stream. It reports an error if the last
+// value is unfinished.
+func (s *scanner) eof() error {
+	if s.err != nil {
+		return s.err
+	}
+
+	switch s.state {
+	case stateEnd, stateSep:
+		return nil
+	case stateZero, stateInt, stateFrac, stateExpInt:
+		// a number is only ended by the byte following it.
+		if len(s.st) == 0 {
+			return nil
+		}
+	}
+	return s.fail("unexpected end of JSON input")
+}
+
+// step reads the byte c in the current state.
+func (s *scanner) step(c byte) int {
+	switch s.state {
+	case stateBegin:
+		if isSpace(c) {
+			return scanSpace
+		}
+		return s.value(c)
+	case stateEnd:
+		if isSpace(c) {
+			s.state = stateSep
+			return scanSpace
+		}
+		return s.invalid(c, "after top-level value")
+	case stateSep:
+		if isSpace(c) {
+			return scanSpace
+		}
+		if r := s.value(c); r != scanContinue {
+			return r
+		}
+		return scanNext
+	case stateValue:
+		if isSpace(c) {
+			return scanSpace
+		}
+		return s.value(c)
+	case stateValueOrEnd:
+		if isSpace(c) {
+			return scanSpace
+		}
+		if c == rBracket {
+			return s.end()
+		}
+		return s.value(c)
+	case stateKeyOrEnd, stateKey:
+		if isSpace(c) {
+			return scanSpace
+		}
+		if c == rBrace && s.state == stateKeyOrEnd {
+			return s.end()
+		}
+		if c == quoteMark {
+			s.key = true
+			s.state = stateString
+			return scanContinue
+		}
+		return s.invalid(c, "looking for beginning of object key string")
+	case stateColon:
+		if isSpace(c) {
+			return scanSpace
+		}
+		if c == colon {
+			s.state = stateValue
+			return scanContinue
+		}
+		return s.invalid(c, "after object key")
+	case stateNext:
+		if isSpace(c) {
+			return scanSpace
+		}
+		kind := s.st[len(s.st)-1]
+		switch {
+		case c == delim && kind == lBrace:
+			s.state = stateKey
+			return scanContinue
+		case c == delim:
+			s.state = stateValue
+			return scanContinue
+		case c == rBrace && kind == lBrace, c == rBracket && kind == lBracket:
+			return s.end()
+		case kind == lBrace:
+			return s.invalid(c, "after object key:value pair")
+		}
+		return s.invalid(c, "after array element")
+	case stateString:
+		switch {
+		case c == quoteMark:
+			if s.key {
+				s.key = false
+				s.state = stateColon
+				return scanContinue
+			}
+			s.next()
+		case c == backslash:
+			s.state = stateEscape
+		case c < space:
+			return s.invalid(c, "in string literal")
+		}
+		return scanContinue
+	case stateEscape:
+		switch c {
+		case 'b', 'f', 'n', 'r', 't', backslash, '/', quoteMark:
+			s.state = stateString
+		case 'u':
+			s.hex = 4
+			s.state = stateHex
+		default:
+			return s.invalid(c, "in string escape code")
+		}
+		return scanContinue
+	case stateHex:
+		if !isHex(c) {
+			return s.invalid(c, "in \\u hexadecimal character escape")
+		}
+		s.hex--
+		if s.hex == 0 {
+			s.state = stateString
+		}
+		return scanContinue
+	case stateNeg:
+		switch {
+		case c == '0':
+			s.state = stateZero
+		case c >= '1' && c <= '9':
+			s.state = stateInt
+		default:
+			return s.invalid(c, "in numeric literal")
+		}
+		return scanContinue
+	case stateZero, stateInt:
+		switch {
+		case c >= '0' && c <= '9' && s.state == stateInt:
+			return scanContinue
+		case c == '.':
+			s.state = stateDot
+			return scanContinue
+		case c == 'e' || c == 'E':
+			s.state = stateExp
+			return scanContinue
+		}
+		return s.endNumber(c)
+	case stateDot:
+		if c < '0' || c > '9' {
+			return s.invalid(c, "after decimal point in numeric literal")
+		}
+		s.state = stateFrac
+		return scanContinue
+	case stateFrac:
+		switch {
+		case c >= '0' && c <= '9':
+			return scanContinue
+		case c == 'e' || c == 'E':
+			s.state = stateExp
+			return scanContinue
+		}
+		return s.endNumber(c)
+	case stateExp:
+		if c == '+' || c == '-' {
+			s.state = stateExpSign
+			return scanContinue
+		}
+		fallthrough
+	case stateExpSign:
+		if c < '0' || c > '9' {
+			return s.invalid(c, "in exponent of numeric literal")
+		}
+		s.state = stateExpInt
+		return scanContinue
+	case stateExpInt:
+		if c >= '0' && c <= '9' {
+			return scanContinue
+		}
+		return s.endNumber(c)
+	case stateLiteral:
+		if c != s.lit[s.li] {
+			return s.invalid(c, "in literal "+s.lit+" (expecting "+strconv.QuoteRune(rune(s.lit[s.li]))+")")
+		}
+		s.li++
+		if s.li == len(s.lit) {
+			s.next()
+		}
+		return scanContinue
+	}
+
+	return scanError
+}
+
+// value reads the first byte of a value.
+func (s *scanner) value(c byte) int {
+	switch {
+	case c == lBrace, c == lBracket:
+		if len(s.st) >= maxScanDepth {
+			s.fail("exceeded max depth")
+			return scanError
+		}
+		s.st = append(s.st, c)
+		s.state = stateKeyOrEnd
+		if c == lBracket {
+			s.state = stateValueOrEnd
+		}
+	case c == quoteMark:
+		s.state = stateString
+	case c == '-':
+		s.state = stateNeg
+	case c == '0':
+		s.state = stateZero
+	case c >= '1' && c <= '9':
+		s.state = stateInt
+	case c == 't':
+		s.literal("true")
+	case c == 'f':
+		s.literal("false")
+	case c == 'n':
+		s.literal("null")
+	default:
+		return s.invalid(c, "looking for beginning of value")
+	}
+	return scanContinue
+}
+
+// literal starts reading the literal lit, of which the first byte is read.
+func (s *scanner) literal(lit string) {
+	s.lit = lit
+	s.li = 1
+	s.state = stateLiteral
+}
+
+// end reads the end of the innermost container.
+func (s *scanner) end() int {
+	s.st = s.st[:len(s.st)-1]
+	s.next()
+	return scanContinue
+}
+
+// endNumber reads the byte following a number, which ends it.
+func (s *scanner) endNumber(c byte) int {
+	s.next()
+	return s.step(c)
+}
+
+// next is called after a value has been read.
+func (s *scanner) next() {
+	if len(s.st) == 0 {
+		s.state = stateEnd
+		return
+	}
+	s.state = stateNext
+}
+
+// invalid records a SyntaxError for the invalid byte c.
+func (s *scanner) invalid(c byte, context string) int {
+	s.fail("invalid character " + quoteChar(c) + " " + context)
+	return scanError
+}
+
+// fail records a SyntaxError at the current offset.
+func (s *scanner) fail(msg string) error {
+	if s.err == nil {
+		s.err = &SyntaxError{Msg: msg, Offset: s.n}
+	}
+	return s.err
+}
+
+// quoteChar formats c as a quoted character literal, like encoding/json.
+func quoteChar(c byte) string {
+	if c == '\'' {
+		return `'\''`
+	}
+	if c == '"' {
+		return `'"'`
+	}
+
+	s := strconv.Quote(string(rune(c)))
+	return "'" + s[1:len(s)-1] + "'"
+}
+
+// isSpace reports whether c is JSON whitespace.
+func isSpace(c byte) bool {
+	return c == space || c == newLine || c == tab || c == '\r'
+}
+
+// isHex reports whether c is a hexadecimal digit.
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
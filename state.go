@@ -0,0 +1,259 @@
+package encoder
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// frame tracks the state of a single object or array being encoded.
+//
+// The Encoder keeps a stack of frames, pushed by ObjectStart and ArrayStart
+// and popped by ObjectEnd and ArrayEnd. With it the Encoder knows when a
+// delimiter is due, so the Field methods and the bare element writers never
+// need an append_delim argument. At the top level no delimiters are written.
+type frame struct {
+	kind  byte // lBrace or lBracket.
+	comma bool // a value has been written, the next one needs a delimiter.
+	key   bool // a key has been written, the next value belongs to it.
+	delim bool // strict: a delimiter has been written explicitly.
+	named bool // strict: a key has been written to this object.
+	n     int  // strict: number of values written.
+	k     int  // strict: offset of the current key in the key path.
+}
+
+// SyntaxError describes structurally invalid JSON. In Strict mode, the
+// Encoder records a SyntaxError instead of writing invalid output.
+type SyntaxError struct {
+	Msg    string // description of the error.
+	Offset int64  // byte offset in the output at which the error occurred.
+	Path   string // nesting path, i.e. $.foo[2].bar
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("encoder: %s at offset %d", e.Msg, e.Offset)
+	}
+	return fmt.Sprintf("encoder: %s at offset %d (%s)", e.Msg, e.Offset, e.Path)
+}
+
+// top returns the innermost frame, or nil at the top level.
+func (enc *Encoder) top() *frame {
+	if len(enc.st) == 0 {
+		return nil
+	}
+	return &enc.st[len(enc.st)-1]
+}
+
+// push a new object or array frame.
+func (enc *Encoder) push(kind byte) {
+	enc.st = append(enc.st, frame{kind: kind, k: len(enc.p)})
+}
+
+// end pops the innermost frame, which must be of the given kind in Strict
+// mode. Otherwise unbalanced calls at the top level are ignored.
+func (enc *Encoder) end(kind byte) {
+	f := enc.top()
+
+	if enc.c.Strict {
+		switch {
+		case f == nil && kind == lBrace:
+			enc.fail("ObjectEnd without ObjectStart")
+		case f == nil:
+			enc.fail("ArrayEnd without ArrayStart")
+		case f.kind != kind && kind == lBrace:
+			enc.fail("ObjectEnd closes an array")
+		case f.kind != kind:
+			enc.fail("ArrayEnd closes an object")
+		case f.key:
+			enc.fail("key without a value")
+		case f.delim:
+			enc.fail("trailing delimiter")
+		}
+	}
+
+	if f == nil {
+		return
+	}
+
+	enc.p = enc.p[:f.k]
+	enc.st = enc.st[:len(enc.st)-1]
+}
+
+// beginKey is called before an object key is written. It inserts a delimiter
+// if the previous member has not been followed by one.
+func beginKey[T []byte | string](enc *Encoder, key T) {
+	f := enc.top()
+
+	if enc.c.Strict {
+		switch {
+		case f == nil || f.kind != lBrace:
+			enc.fail("key outside of an object")
+		case f.key:
+			enc.fail("key without a value")
+		}
+	}
+
+	if f == nil {
+		return
+	}
+
+	if f.comma {
+		enc.appendByte(delim)
+		f.comma = false
+	}
+	f.key = true
+	f.delim = false
+
+	if enc.c.Strict {
+		enc.p = append(enc.p[:f.k], key...)
+		f.named = true
+	}
+}
+
+// beginValue is called before a value is written. A value following a key
+// needs no delimiter; an array element does if it is not the first one.
+func (enc *Encoder) beginValue() {
+	f := enc.top()
+	if f == nil {
+		return
+	}
+
+	if enc.c.Strict && f.kind == lBrace && !f.key {
+		enc.fail("value without a key")
+	}
+
+	f.delim = false
+	if f.key {
+		f.key = false
+		return
+	}
+
+	if f.comma {
+		enc.appendByte(delim)
+		f.comma = false
+	}
+}
+
+// endValue is called after a value has been written.
+func (enc *Encoder) endValue() {
+	if f := enc.top(); f != nil {
+		f.comma = true
+		f.n++
+	}
+}
+
+// delimited is called when a delimiter is written explicitly.
+func (enc *Encoder) delimited() {
+	f := enc.top()
+
+	if enc.c.Strict {
+		switch {
+		case f == nil:
+			enc.fail("delimiter outside of an object or array")
+		case f.key:
+			enc.fail("delimiter after a key")
+		case !f.comma:
+			enc.fail("unexpected delimiter")
+		}
+	}
+
+	if f != nil {
+		f.comma = false
+		f.delim = true
+	}
+}
+
+// fail records a SyntaxError at the current offset and nesting path.
+func (enc *Encoder) fail(msg string) {
+	if enc.err != nil {
+		return
+	}
+
+	enc.setErr(&SyntaxError{
+		Msg:    msg,
+		Offset: enc.n + int64(enc.b.Len()),
+		Path:   enc.path(),
+	})
+}
+
+// path returns the current nesting path, i.e. $.foo[2].bar
+func (enc *Encoder) path() string {
+	var sb strings.Builder
+	sb.WriteByte('$')
+
+	for i := range enc.st {
+		f := &enc.st[i]
+		if f.kind == lBracket {
+			sb.WriteByte(lBracket)
+			sb.WriteString(strconv.Itoa(f.n))
+			sb.WriteByte(rBracket)
+			continue
+		}
+
+		if !f.named {
+			continue
+		}
+
+		end := len(enc.p)
+		if i+1 < len(enc.st) {
+			end = enc.st[i+1].k
+		}
+		sb.WriteByte('.')
+		sb.Write(enc.p[f.k:end])
+	}
+
+	return sb.String()
+}
+
+// Depth returns the number of objects and arrays that are currently open.
+func (enc *Encoder) Depth() int {
+	return len(enc.st)
+}
+
+// Uint32Field writes the key and value. Delimiters are managed by the Encoder.
+func (enc *Encoder) Uint32Field(key []byte, value uint32) {
+	enc.writeUint64Key(key, uint64(value), false, false)
+}
+
+// EncodedUint32Field writes the key and quoted value. Delimiters are managed
+// by the Encoder.
+func (enc *Encoder) EncodedUint32Field(key []byte, value uint32) {
+	enc.writeUint64Key(key, uint64(value), false, true)
+}
+
+// Uint64Field writes the key and value. Delimiters are managed by the Encoder.
+func (enc *Encoder) Uint64Field(key []byte, value uint64) {
+	enc.writeUint64Key(key, value, false, false)
+}
+
+// EncodedUint64Field writes the key and quoted value. Delimiters are managed
+// by the Encoder.
+func (enc *Encoder) EncodedUint64Field(key []byte, value uint64) {
+	enc.writeUint64Key(key, value, false, true)
+}
+
+// Float64Field writes the key and value. Delimiters are managed by the
+// Encoder.
+func (enc *Encoder) Float64Field(key []byte, value float64) {
+	enc.writeFloat64Key(key, value, false, false)
+}
+
+// EncodedFloat64Field writes the key and quoted value. Delimiters are managed
+// by the Encoder.
+func (enc *Encoder) EncodedFloat64Field(key []byte, value float64) {
+	enc.writeFloat64Key(key, value, false, true)
+}
+
+// Uint32TimestampField writes the key and the unix timestamp as an ISO8601
+// value. Delimiters are managed by the Encoder.
+func (enc *Encoder) Uint32TimestampField(key []byte, value uint32) {
+	enc.WriteUint32Timestamp(key, value, false)
+}
+
+// TimeField writes the key and the time as an ISO8601 value. Delimiters are
+// managed by the Encoder.
+func (enc *Encoder) TimeField(key []byte, value time.Time) {
+	enc.WriteTimeKey(key, value, false)
+}
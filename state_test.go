@@ -0,0 +1,289 @@
+package encoder
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncoderState(t *testing.T) {
+	t.Run("fields", func(t *testing.T) {
+		enc := GetEncoder(nil)
+		defer enc.Release()
+		enc.c.UTCTimestamps = true
+
+		enc.ObjectStart()
+		enc.Uint32Field([]byte("a"), 1)
+		enc.EncodedUint64Field([]byte("b"), 2)
+		enc.ObjectKey([]byte("c"))
+		enc.ArrayStart()
+		enc.ObjectStart()
+		enc.Float64Field([]byte("d"), 0.5)
+		enc.ObjectEnd()
+		enc.ObjectStart()
+		enc.ObjectEnd()
+		enc.ArrayEnd()
+		enc.Uint32TimestampField([]byte("e"), 0)
+		enc.ObjectEnd()
+
+		assert.True(t, json.Valid(enc.Bytes()))
+		assert.Equal(t, `{"a":1,"b":"2","c":[{"d":0.5},{}],"e":"1970-01-01T00:00:00+00"}`, string(enc.Bytes()))
+		assert.Equal(t, 0, enc.Depth())
+	})
+
+	t.Run("append_delim", func(t *testing.T) {
+		enc := GetEncoder(nil)
+		defer enc.Release()
+
+		enc.ObjectStart()
+		enc.WriteUint64Key([]byte("a"), 1, true)
+		enc.WriteUint64Key([]byte("b"), 2, true)
+		enc.ObjectKey([]byte("c"))
+		enc.ArrayStart()
+		enc.ArrayEnd()
+		enc.Delim()
+		enc.WriteUint64Key([]byte("d"), 3, false)
+		enc.ObjectEnd()
+
+		assert.Equal(t, `{"a":1,"b":2,"c":[],"d":3}`, string(enc.Bytes()))
+	})
+
+	t.Run("missing delim", func(t *testing.T) {
+		enc := GetEncoder(nil)
+		defer enc.Release()
+
+		enc.ObjectStart()
+		enc.WriteUint64Key([]byte("a"), 1, false)
+		enc.WriteUint64Key([]byte("b"), 2, false)
+		enc.ObjectEnd()
+
+		assert.Equal(t, `{"a":1,"b":2}`, string(enc.Bytes()))
+	})
+
+	t.Run("appended delim", func(t *testing.T) {
+		enc := GetEncoder(nil)
+		defer enc.Release()
+
+		enc.ArrayStart()
+		enc.ObjectStart()
+		enc.ObjectEnd()
+		enc.AppendByte(',')
+		enc.ObjectStart()
+		enc.ObjectEnd()
+		enc.AppendBytes([]byte(","))
+		enc.Uint64(1)
+		enc.ArrayEnd()
+
+		assert.Equal(t, `[{},{},1]`, string(enc.Bytes()))
+	})
+
+	t.Run("old api", func(t *testing.T) {
+		enc := GetEncoder(nil)
+		defer enc.Release()
+
+		enc.ObjectStart()
+		enc.ObjectKey([]byte("a"))
+		enc.EncodeKey([]byte("x"))
+		enc.Uint64Field([]byte("b"), 2)
+		enc.ObjectKey([]byte("c"))
+		enc.AppendBytes([]byte("3"))
+		enc.ObjectKey([]byte("d"))
+		enc.AppendByte('"')
+		enc.AppendBytes([]byte("y"))
+		enc.AppendByte('"')
+		enc.AppendBytes([]byte(`,"e":`))
+		enc.Uint64(4)
+		enc.Uint64Field([]byte("f"), 5)
+		enc.ObjectEnd()
+
+		assert.Equal(t, `{"a":"x","b":2,"c":3,"d":"y","e":4,"f":5}`, string(enc.Bytes()))
+	})
+
+	t.Run("depth", func(t *testing.T) {
+		enc := GetEncoder(nil)
+		defer enc.Release()
+
+		enc.ArrayStart()
+		enc.ObjectStart()
+		assert.Equal(t, 2, enc.Depth())
+		enc.ObjectEnd()
+		enc.ArrayEnd()
+		assert.Equal(t, 0, enc.Depth())
+
+		// unbalanced ends are ignored.
+		enc.ArrayEnd()
+		assert.Equal(t, 0, enc.Depth())
+	})
+}
+
+func BenchmarkEncoderUint64Field(b *testing.B) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	value := uint64(473829)
+	key := []byte("/foo/bar")
+	enc.ObjectStart()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		enc.b.Reset()
+		enc.Uint64Field(key, value)
+	}
+}
+
+func TestEncoderStrict(t *testing.T) {
+	strict := func() *Encoder {
+		enc := GetEncoder(nil)
+		enc.c.Strict = true
+		return enc
+	}
+
+	syntaxError := func(t *testing.T, err error) *SyntaxError {
+		var serr *SyntaxError
+		assert.ErrorAs(t, err, &serr)
+		return serr
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		enc := strict()
+		defer enc.Release()
+
+		enc.ObjectStart()
+		enc.WriteUint64Key([]byte("a"), 1, true)
+		enc.ObjectKey([]byte("b"))
+		enc.ArrayStart()
+		enc.ObjectStart()
+		enc.ObjectEnd()
+		enc.ArrayEnd()
+		enc.ObjectEnd()
+		assert.NoError(t, enc.Err())
+		assert.Equal(t, `{"a":1,"b":[{}]}`, string(enc.Bytes()))
+	})
+
+	t.Run("old style string", func(t *testing.T) {
+		enc := strict()
+		defer enc.Release()
+
+		enc.ObjectStart()
+		enc.ObjectKey([]byte("a"))
+		enc.EncodeKey([]byte("x"))
+		enc.Delim()
+		enc.ObjectKey([]byte("b"))
+		enc.EncodeKey([]byte("y"))
+		enc.ObjectEnd()
+		assert.NoError(t, enc.Err())
+		assert.Equal(t, `{"a":"x","b":"y"}`, string(enc.Bytes()))
+	})
+
+	t.Run("key outside of object", func(t *testing.T) {
+		enc := strict()
+		defer enc.Release()
+
+		enc.ArrayStart()
+		enc.ObjectKey([]byte("a"))
+		err := syntaxError(t, enc.Err())
+		assert.Equal(t, "key outside of an object", err.Msg)
+		assert.Equal(t, int64(1), err.Offset)
+		assert.Equal(t, "$[0]", err.Path)
+
+		// the key has not been written.
+		assert.Equal(t, "[", string(enc.Bytes()))
+	})
+
+	t.Run("value without key", func(t *testing.T) {
+		enc := strict()
+		defer enc.Release()
+
+		enc.ObjectStart()
+		enc.ObjectStart()
+		err := syntaxError(t, enc.Err())
+		assert.Equal(t, "value without a key", err.Msg)
+	})
+
+	t.Run("key without value", func(t *testing.T) {
+		enc := strict()
+		defer enc.Release()
+
+		enc.ObjectStart()
+		enc.ObjectKey([]byte("a"))
+		enc.ObjectEnd()
+		err := syntaxError(t, enc.Err())
+		assert.Equal(t, "key without a value", err.Msg)
+		assert.Equal(t, "$.a", err.Path)
+	})
+
+	t.Run("mismatched end", func(t *testing.T) {
+		enc := strict()
+		defer enc.Release()
+
+		enc.ObjectStart()
+		enc.ObjectKey([]byte("foo"))
+		enc.ArrayStart()
+		enc.WriteUint64Key([]byte("bar"), 1, false)
+		assert.Equal(t, "key outside of an object", syntaxError(t, enc.Err()).Msg)
+
+		enc = strict()
+		defer enc.Release()
+		enc.ObjectStart()
+		enc.ObjectKey([]byte("foo"))
+		enc.ArrayStart()
+		enc.ObjectStart()
+		enc.ObjectEnd()
+		enc.ObjectEnd()
+		err := syntaxError(t, enc.Err())
+		assert.Equal(t, "ObjectEnd closes an array", err.Msg)
+		assert.Equal(t, int64(10), err.Offset)
+		assert.Equal(t, "$.foo[1]", err.Path)
+	})
+
+	t.Run("end without start", func(t *testing.T) {
+		enc := strict()
+		defer enc.Release()
+
+		enc.ArrayEnd()
+		assert.Equal(t, "ArrayEnd without ArrayStart", syntaxError(t, enc.Err()).Msg)
+	})
+
+	t.Run("trailing delimiter", func(t *testing.T) {
+		enc := strict()
+		defer enc.Release()
+
+		enc.ObjectStart()
+		enc.WriteUint64Key([]byte("a"), 1, true)
+		enc.ObjectEnd()
+		assert.Equal(t, "trailing delimiter", syntaxError(t, enc.Err()).Msg)
+		assert.Equal(t, `{"a":1,`, string(enc.Bytes()))
+	})
+
+	t.Run("unexpected delimiter", func(t *testing.T) {
+		enc := strict()
+		defer enc.Release()
+
+		enc.ArrayStart()
+		enc.Delim()
+		assert.Equal(t, "unexpected delimiter", syntaxError(t, enc.Err()).Msg)
+
+		enc = strict()
+		defer enc.Release()
+		enc.Delim()
+		assert.Equal(t, "delimiter outside of an object or array", syntaxError(t, enc.Err()).Msg)
+	})
+
+	t.Run("close", func(t *testing.T) {
+		r, w := io.Pipe()
+		enc := GetEncoder(w)
+		defer enc.Release()
+		enc.c.Strict = true
+		go io.Copy(io.Discard, r)
+
+		enc.ObjectStart()
+		enc.ObjectKey([]byte("a"))
+		enc.ArrayStart()
+		err := syntaxError(t, enc.Close())
+		assert.Equal(t, "unclosed object or array", err.Msg)
+		assert.Equal(t, "$.a[0]", err.Path)
+		assert.Equal(t, int64(0), enc.n)
+		assert.EqualError(t, err, "encoder: unclosed object or array at offset 6 ($.a[0])")
+	})
+}
@@ -0,0 +1,60 @@
+package encoder
+
+// WriteStrBytesKey writes the key and the escaped string value.
+func (enc *Encoder) WriteStrBytesKey(key []byte, value []byte, append_delim bool) {
+	enc.ObjectKey(key)
+	writeString(enc, value)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteStrKey writes the key and the escaped string value.
+func (enc *Encoder) WriteStrKey(key []byte, value string, append_delim bool) {
+	enc.ObjectKey(key)
+	writeString(enc, value)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteStrBytes writes the escaped string value as an array element.
+func (enc *Encoder) WriteStrBytes(value []byte, append_delim bool) {
+	writeString(enc, value)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// WriteStr writes the escaped string value as an array element.
+func (enc *Encoder) WriteStr(value string, append_delim bool) {
+	writeString(enc, value)
+
+	if append_delim {
+		enc.Delim()
+	}
+}
+
+// StrBytesField writes the key and the escaped string value. Delimiters are
+// managed by the Encoder.
+func (enc *Encoder) StrBytesField(key []byte, value []byte) {
+	enc.WriteStrBytesKey(key, value, false)
+}
+
+// StrField writes the key and the escaped string value. Delimiters are
+// managed by the Encoder.
+func (enc *Encoder) StrField(key []byte, value string) {
+	enc.WriteStrKey(key, value, false)
+}
+
+// writeString writes the escaped value enclosed in quotation marks.
+func writeString[T []byte | string](enc *Encoder, value T) {
+	enc.beginValue()
+	enc.appendByte(quoteMark)
+	writeEscaped(enc, value)
+	enc.appendByte(quoteMark)
+	enc.endValue()
+}
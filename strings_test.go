@@ -0,0 +1,79 @@
+package encoder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncoderWriteStrBytesKey(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	key := []byte("/foo/bar")
+	enc.WriteStrBytesKey(key, []byte(`say "hi"`), true)
+	assert.Equal(t, `"/foo/bar":"say \"hi\"",`, enc.b.String())
+
+	enc.b.Reset()
+	enc.WriteStrBytesKey(key, []byte("a\\b"), false)
+	assert.Equal(t, `"/foo/bar":"a\\b"`, enc.b.String())
+}
+
+func TestEncoderWriteStrKey(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	key := []byte("/foo/bar")
+	enc.WriteStrKey(key, "tab\there", true)
+	assert.Equal(t, `"/foo/bar":"tab\there",`, enc.b.String())
+
+	enc.b.Reset()
+	enc.WriteStrKey(key, "", false)
+	assert.Equal(t, `"/foo/bar":""`, enc.b.String())
+}
+
+func TestEncoderWriteStrBytes(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+
+	enc.ArrayStart()
+	enc.WriteStrBytes([]byte("a"), true)
+	enc.WriteStr(`"b"`, false)
+	enc.ArrayEnd()
+	assert.Equal(t, `["a","\"b\""]`, enc.b.String())
+}
+
+func TestEncoderStrBytesField(t *testing.T) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+
+	enc.ObjectStart()
+	enc.StrBytesField([]byte("a"), []byte("1"))
+	enc.StrField([]byte("b"), "2")
+	enc.ObjectEnd()
+	assert.Equal(t, `{"a":"1","b":"2"}`, enc.b.String())
+}
+
+func BenchmarkEncoderWriteStrBytesKey(b *testing.B) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	key := []byte("/foo/bar")
+	value := []byte("the quick brown fox")
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		enc.b.Reset()
+		enc.WriteStrBytesKey(key, value, true)
+	}
+}
+
+func BenchmarkEncoderWriteStrKey(b *testing.B) {
+	enc := GetEncoder(nil)
+	defer enc.Release()
+	key := []byte("/foo/bar")
+	value := "the quick brown fox"
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		enc.b.Reset()
+		enc.WriteStrKey(key, value, true)
+	}
+}